@@ -2,13 +2,22 @@
 package interchaintest_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"time"
+
+	"cosmossdk.io/math"
 
 	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
 	"github.com/strangelove-ventures/interchaintest/v8/ibc"
@@ -17,9 +26,180 @@ import (
 // DefaultLumeraVersion is used when LUMERA_VERSION env var is not set.
 const DefaultLumeraVersion = "v1.10.1"
 
+// LumeraOption customizes the chain config returned by GetLumeraChainConfig.
+type LumeraOption func(*lumeraOptions)
+
+// lumeraOptions collects per-test overrides applied on top of the built-in
+// genesis modifications in modifyLumeraGenesis.
+type lumeraOptions struct {
+	// version is the Lumera release under test; it selects the
+	// lumeraReleases entry.
+	version    string
+	genesisKVs []cosmos.GenesisKV
+	// claimsCSV is injected into every node and drives the genesis claims
+	// total. It defaults to the host claims.csv; nil falls back to the copy
+	// baked into the image.
+	claimsCSV []byte
+	// claimsErr is the error reading the host claims.csv, surfaced by
+	// modifyGenesis in strict mode.
+	claimsErr error
+	// claimEndOffset, if set, places claim_end_time relative to genesis_time.
+	claimEndOffset *time.Duration
+	// overridesPath is a genesis overrides file (see genesis_overrides.go)
+	// applied last. It defaults to GENESIS_OVERRIDES.
+	overridesPath string
+	// upgradeTo is the version the chain will be upgraded to in place, if
+	// any (see WithUpgradeTo).
+	upgradeTo string
+}
+
+// WithGenesisKV sets a dot-separated genesis path after the built-in
+// modifications have been applied.
+func WithGenesisKV(key string, value interface{}) LumeraOption {
+	return func(o *lumeraOptions) {
+		o.genesisKVs = append(o.genesisKVs, cosmos.NewGenesisKV(key, value))
+	}
+}
+
+// WithSupernodeMinimumStake lowers supernode.params.minimum_stake_for_sn so
+// tests can register supernodes without bonding 10,000 LUME each.
+func WithSupernodeMinimumStake(amount math.Int) LumeraOption {
+	return WithGenesisKV("app_state.supernode.params.minimum_stake_for_sn.amount", amount.String())
+}
+
+// WithActionExpiration sets action.params.expiration_duration, the minimum
+// lifetime a requested action must have before it expires unprocessed.
+func WithActionExpiration(d time.Duration) LumeraOption {
+	return WithGenesisKV("app_state.action.params.expiration_duration", durationParam(d))
+}
+
+// WithActionProcessingTime sets action.params.min_processing_time and
+// max_processing_time.
+func WithActionProcessingTime(min, max time.Duration) LumeraOption {
+	return func(o *lumeraOptions) {
+		WithGenesisKV("app_state.action.params.min_processing_time", durationParam(min))(o)
+		WithGenesisKV("app_state.action.params.max_processing_time", durationParam(max))(o)
+	}
+}
+
+// WithActionMaxPerBlock sets action.params.max_actions_per_block.
+func WithActionMaxPerBlock(n uint64) LumeraOption {
+	return WithGenesisKV("app_state.action.params.max_actions_per_block", fmt.Sprint(n))
+}
+
+// WithClaimsCSV makes the nodes load csv (see ClaimsCSV) instead of the host
+// claims.csv.
+func WithClaimsCSV(csv []byte) LumeraOption {
+	return func(o *lumeraOptions) {
+		o.claimsCSV = csv
+	}
+}
+
+// WithClaimEndTime sets claim.params.claim_end_time to genesis_time+offset,
+// so the claim window can be closed within a test.
+func WithClaimEndTime(offset time.Duration) LumeraOption {
+	return func(o *lumeraOptions) {
+		o.claimEndOffset = &offset
+	}
+}
+
+// WithMaxClaimsPerBlock sets claim.params.max_claims_per_block.
+func WithMaxClaimsPerBlock(n uint64) LumeraOption {
+	return WithGenesisKV("app_state.claim.params.max_claims_per_block", fmt.Sprint(n))
+}
+
+// WithICAControllerEnabled sets the ICA controller module's
+// controller_enabled param, which decides whether Lumera accounts may own
+// interchain accounts on other chains.
+func WithICAControllerEnabled(enabled bool) LumeraOption {
+	return WithGenesisKV("app_state.interchainaccounts.controller_genesis_state.params.controller_enabled", enabled)
+}
+
+// durationParam renders d the way genesis encodes duration params ("60s").
+func durationParam(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+
+// modifyGenesis runs modifyLumeraGenesis followed by the option overrides
+// and, last, the genesis overrides file.
+func (o *lumeraOptions) modifyGenesis(config ibc.ChainConfig, genesis []byte) ([]byte, error) {
+	if o.claimsErr != nil && claimsStrictFromEnv() {
+		return nil, o.claimsErr
+	}
+	genesis, err := modifyLumeraGenesis(config, o.version, genesis, o.claimsCSV)
+	if err != nil {
+		return nil, err
+	}
+	if o.claimEndOffset != nil {
+		if genesis, err = setClaimEndTime(config, genesis, *o.claimEndOffset); err != nil {
+			return nil, err
+		}
+	}
+	if len(o.genesisKVs) > 0 {
+		if genesis, err = cosmos.ModifyGenesis(o.genesisKVs)(config, genesis); err != nil {
+			return nil, err
+		}
+	}
+	if o.overridesPath == "" {
+		return genesis, nil
+	}
+	overrides, err := readGenesisOverrides(o.overridesPath)
+	if err != nil {
+		return nil, err
+	}
+	return applyGenesisOverrides(genesis, overrides)
+}
+
+// setClaimEndTime sets claim_end_time to genesis_time+offset (unix seconds).
+func setClaimEndTime(config ibc.ChainConfig, genesis []byte, offset time.Duration) ([]byte, error) {
+	var g struct {
+		GenesisTime time.Time `json:"genesis_time"`
+	}
+	if err := json.Unmarshal(genesis, &g); err != nil {
+		return nil, fmt.Errorf("failed to read genesis_time: %w", err)
+	}
+	end := g.GenesisTime.Add(offset).Unix()
+	return cosmos.ModifyGenesis([]cosmos.GenesisKV{
+		cosmos.NewGenesisKV("app_state.claim.params.claim_end_time", fmt.Sprint(end)),
+	})(config, genesis)
+}
+
+// preGenesis writes claims.csv into every node's home directory, so the
+// nodes load exactly the file the genesis total was computed from rather
+// than whatever the image was built with.
+func (o *lumeraOptions) preGenesis(chain ibc.Chain) error {
+	if o.claimsCSV == nil {
+		return nil
+	}
+	ctx := context.Background()
+	for _, node := range chain.(*cosmos.CosmosChain).Nodes() {
+		if err := node.WriteFile(ctx, o.claimsCSV, "config/claims.csv"); err != nil {
+			return fmt.Errorf("write claims.csv to %s: %w", node.Name(), err)
+		}
+	}
+	return nil
+}
+
+// claimsPath returns the --claims-path the nodes start with.
+func (o *lumeraOptions) claimsPath(config ibc.ChainConfig) string {
+	if o.claimsCSV == nil {
+		return "/tmp/claims.csv"
+	}
+	// Same location as ChainNode.HomeDir()/config/claims.csv.
+	return path.Join("/var/cosmos-chain", config.Name, "config", "claims.csv")
+}
+
 // GetLumeraChainConfig returns a chain config for the given version.
-// version is the Docker image tag (e.g. "v1.10.1").
-func GetLumeraChainConfig(version string, useLocalImage bool) ibc.ChainConfig {
+// version is the Docker image tag (e.g. "v1.10.1"). Options are applied on
+// top of the default genesis modifications.
+func GetLumeraChainConfig(version string, useLocalImage bool, opts ...LumeraOption) ibc.ChainConfig {
+	o := &lumeraOptions{version: version}
+	o.claimsCSV, o.claimsErr = readHostClaimsCSV()
+	o.overridesPath = genesisOverridesPathFromEnv()
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	image := ibc.DockerImage{
 		Repository: "ghcr.io/lumeraprotocol/lumerad",
 		Version:    version,
@@ -31,50 +211,40 @@ func GetLumeraChainConfig(version string, useLocalImage bool) ibc.ChainConfig {
 		image.Version = "local"
 	}
 
-	return ibc.ChainConfig{
-		Type:                "cosmos",
-		Name:                "lumera",
-		ChainID:             "lumera-testnet-2",
-		Images:              []ibc.DockerImage{image},
-		Bin:                 "lumerad",
-		Bech32Prefix:        "lumera",
-		Denom:               "ulume",
-		GasPrices:           "0.025ulume",
-		GasAdjustment:       1.5,
-		TrustingPeriod:      "336h",
-		ModifyGenesis:       modifyLumeraGenesis,
-		AdditionalStartArgs: []string{"--claims-path", "/tmp/claims.csv"},
-	}
-}
-
-var (
-	OsmosisImage = ibc.DockerImage{
-		Repository: "ghcr.io/strangelove-ventures/heighliner/osmosis",
-		Version:    "v25.0.0",
-		UIDGID:     "1025:1025",
-	}
-
-	OsmosisConfig = ibc.ChainConfig{
+	config := ibc.ChainConfig{
 		Type:           "cosmos",
-		Name:           "osmosis",
-		ChainID:        "osmosis-test-1",
-		Images:         []ibc.DockerImage{OsmosisImage},
-		Bin:            "osmosisd",
-		Bech32Prefix:   "osmo",
-		Denom:          "uosmo",
-		GasPrices:      "0.025uosmo",
+		Name:           "lumera",
+		ChainID:        "lumera-testnet-2",
+		Images:         []ibc.DockerImage{image},
+		Bin:            "lumerad",
+		Bech32Prefix:   "lumera",
+		Denom:          "ulume",
+		GasPrices:      "0.025ulume",
 		GasAdjustment:  1.5,
 		TrustingPeriod: "336h",
+		ModifyGenesis:  o.modifyGenesis,
+		PreGenesis:     o.preGenesis,
+	}
+	release := lumeraReleaseFor(version)
+	var target *lumeraRelease
+	if o.upgradeTo != "" {
+		r := lumeraReleaseFor(o.upgradeTo)
+		target = &r
 	}
+	config.Env = lumeraEnv(release, target)
+	config.AdditionalStartArgs = append([]string{"--claims-path", o.claimsPath(config)}, release.startArgs...)
+	return config
+}
 
-	// Default config for backward compatibility
-	LumeraConfig = GetLumeraChainConfig(DefaultLumeraVersion, false)
-)
+// LumeraConfig is the default config, kept for backward compatibility.
+var LumeraConfig = GetLumeraChainConfig(DefaultLumeraVersion, false)
 
 // modifyLumeraGenesis configures genesis for Lumera.
 // Follows the minimal-modification approach: trust lumerad init defaults,
-// only fix denoms + remove unsupported modules.
-func modifyLumeraGenesis(config ibc.ChainConfig, genesis []byte) ([]byte, error) {
+// only fix denoms + remove unsupported modules, then apply the changes the
+// lumeraReleases entry for version needs. claimsCSV is the claims.csv the
+// nodes will load (nil if unknown).
+func modifyLumeraGenesis(config ibc.ChainConfig, version string, genesis []byte, claimsCSV []byte) ([]byte, error) {
 	genesis, err := cosmos.ModifyGenesis([]cosmos.GenesisKV{
 		cosmos.NewGenesisKV("app_state.staking.params.bond_denom", config.Denom),
 		cosmos.NewGenesisKV("app_state.mint.params.mint_denom", config.Denom),
@@ -96,66 +266,175 @@ func modifyLumeraGenesis(config ibc.ChainConfig, genesis []byte) ([]byte, error)
 		return nil, fmt.Errorf("app_state not found in genesis")
 	}
 
-	// Crisis module was removed in v1.10.x
-	delete(appState, "crisis")
 	// Remove unsupported modules
 	delete(appState, "nft")
-	// v1.10.x uses x/consensus module for consensus params
-	if err := setConsensusParams(appState); err != nil {
-		return nil, err
+	if release := lumeraReleaseFor(version); release.modifyGenesis != nil {
+		if err := release.modifyGenesis(appState); err != nil {
+			return nil, fmt.Errorf("lumera %s genesis: %w", release.name, err)
+		}
 	}
 	// Sync claims total from CSV
-	if err := setClaimsTotalFromCSV(appState); err != nil {
+	if err := setClaimsFromCSV(g, claimsCSV); err != nil {
 		return nil, err
 	}
 
 	return json.MarshalIndent(g, "", "  ")
 }
 
-// setClaimsTotalFromCSV reads claims.csv and sets total_claimable_amount in genesis to match
-func setClaimsTotalFromCSV(appState map[string]interface{}) error {
-	// Find claims.csv relative to this source file
-	_, thisFile, _, _ := runtime.Caller(0)
-	claimsPath := filepath.Join(filepath.Dir(thisFile), "claims.csv")
+// claimsChecksumKey is the top-level genesis field recording the SHA-256 of
+// the claims.csv the genesis total was computed from. Genesis decoding
+// ignores unknown top-level fields, so the nodes are unaffected.
+const claimsChecksumKey = "claims_csv_sha256"
 
-	f, err := os.Open(claimsPath)
-	if err != nil {
+// setClaimsFromCSV sets total_claimable_amount in genesis to the total of
+// claimsCSV and records the file's checksum under claimsChecksumKey.
+// In strict mode (see claimsStrictFromEnv) an invalid file is an error;
+// otherwise malformed rows are ignored and an empty total is skipped.
+func setClaimsFromCSV(g map[string]interface{}, claimsCSV []byte) error {
+	if claimsCSV == nil {
 		// claims.csv not available on host — skip
 		return nil
 	}
-	defer f.Close()
 
-	total := new(big.Int)
-	r := csv.NewReader(f)
-	for {
-		record, err := r.Read()
-		if err != nil {
-			break
-		}
-		if len(record) < 2 {
-			continue
-		}
-		amount := new(big.Int)
-		if _, ok := amount.SetString(record[1], 10); !ok {
-			continue
-		}
-		total.Add(total, amount)
+	report, err := parseClaimsCSV(bytes.NewReader(claimsCSV), claimsStrictFromEnv())
+	if err != nil {
+		return fmt.Errorf("claims.csv: %w", err)
 	}
-
-	if total.Sign() == 0 {
+	if report.Total.Sign() == 0 {
 		return nil
 	}
 
+	appState := g["app_state"].(map[string]interface{})
 	claim, ok := appState["claim"].(map[string]interface{})
 	if !ok {
 		claim = make(map[string]interface{})
 		appState["claim"] = claim
 	}
-	claim["total_claimable_amount"] = total.String()
+	claim["total_claimable_amount"] = report.Total.String()
+	g[claimsChecksumKey] = claimsChecksum(claimsCSV)
 
 	return nil
 }
 
+// claimsChecksum returns the hex SHA-256 of a claims.csv.
+func claimsChecksum(claimsCSV []byte) string {
+	sum := sha256.Sum256(claimsCSV)
+	return hex.EncodeToString(sum[:])
+}
+
+// readHostClaimsCSV reads the claims.csv next to this source file.
+func readHostClaimsCSV() ([]byte, error) {
+	bz, err := os.ReadFile(hostClaimsCSVPath())
+	if err != nil {
+		return nil, fmt.Errorf("claims.csv: %w", err)
+	}
+	return bz, nil
+}
+
+// hostClaimsCSVPath returns the path of the claims.csv next to this source
+// file. The local Docker image bakes in the same file, but it may be stale.
+func hostClaimsCSVPath() string {
+	_, thisFile, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(thisFile), "claims.csv")
+}
+
+// claimsStrictFromEnv reports whether claims.csv is validated strictly:
+// CLAIMS_STRICT=true|false if set, otherwise strict on CI (CI=true).
+func claimsStrictFromEnv() bool {
+	if v := os.Getenv("CLAIMS_STRICT"); v != "" {
+		return v == "true"
+	}
+	return os.Getenv("CI") == "true"
+}
+
+// ClaimsCSVReport summarizes a parsed claims.csv.
+type ClaimsCSVReport struct {
+	Rows int
+	// ZeroRows counts rows with a zero amount. Strict mode rejects them,
+	// except for the known upstream rows in claimsCSVZeroAmountAllowlist.
+	ZeroRows int
+	Total    *big.Int
+}
+
+func (r ClaimsCSVReport) String() string {
+	if r.ZeroRows > 0 {
+		return fmt.Sprintf("%d rows (%d with zero amount), total %s", r.Rows, r.ZeroRows, r.Total)
+	}
+	return fmt.Sprintf("%d rows, total %s", r.Rows, r.Total)
+}
+
+// claimsCSVZeroAmountAllowlist holds the addresses that upstream's claims.csv
+// lists with a zero amount. They claim nothing but are kept so the file stays
+// byte-identical to upstream; strict mode accepts them as known exceptions and
+// reports them in ClaimsCSVReport.ZeroRows.
+var claimsCSVZeroAmountAllowlist = map[string]bool{
+	"Ptfi8JvnepB6toAxMBUerBDzXYq8DS1sE7Y": true,
+}
+
+// parseClaimsCSV sums the amount column of a claims.csv.
+//
+// In strict mode every row must be "<pastel address>,<positive integer>"
+// with a valid address checksum, addresses must be unique and the file must
+// not be empty; the first violation is returned with its line number. Zero
+// amounts are only accepted for claimsCSVZeroAmountAllowlist.
+// Otherwise malformed rows are skipped and reading stops at the first error.
+func parseClaimsCSV(r io.Reader, strict bool) (ClaimsCSVReport, error) {
+	report := ClaimsCSVReport{Total: new(big.Int)}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // row shape is checked below
+	seen := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if strict {
+				return report, err
+			}
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) != 2 {
+			if strict {
+				return report, fmt.Errorf("line %d: want 2 fields (address,amount), got %d", line, len(record))
+			}
+			if len(record) < 2 {
+				continue
+			}
+		}
+		addr := record[0]
+		amount := new(big.Int)
+		if _, ok := amount.SetString(record[1], 10); !ok {
+			if strict {
+				return report, fmt.Errorf("line %d: invalid amount %q for %s", line, record[1], addr)
+			}
+			continue
+		}
+		if strict {
+			if amount.Sign() <= 0 && !(amount.Sign() == 0 && claimsCSVZeroAmountAllowlist[addr]) {
+				return report, fmt.Errorf("line %d: non-positive amount %s for %s", line, amount, addr)
+			}
+			if err := validatePastelAddress(addr); err != nil {
+				return report, fmt.Errorf("line %d: %w", line, err)
+			}
+			if prev, dup := seen[addr]; dup {
+				return report, fmt.Errorf("line %d: duplicate address %s (first on line %d)", line, addr, prev)
+			}
+			seen[addr] = line
+		}
+		report.Rows++
+		if amount.Sign() == 0 {
+			report.ZeroRows++
+		}
+		report.Total.Add(report.Total, amount)
+	}
+	if strict && report.Rows == 0 {
+		return report, fmt.Errorf("no claim rows")
+	}
+	return report, nil
+}
+
 // setConsensusParams configures consensus params in x/consensus module
 func setConsensusParams(appState map[string]interface{}) error {
 	consensus, ok := appState["consensus"].(map[string]interface{})
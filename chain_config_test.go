@@ -0,0 +1,296 @@
+// chain_config_test.go - Docker-free tests of the genesis modifications in
+// chain_config.go, run against the checked-in genesis.json.
+package interchaintest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testGenesisPath is the mainnet-derived genesis shipped with the repo.
+const testGenesisPath = "genesis.json"
+
+// goldenGenesisPath is the expected modifyLumeraGenesis output for
+// testGenesisPath and the host claims.csv.
+var goldenGenesisPath = filepath.Join("testdata", "genesis.golden.json")
+
+// The -update flag is already registered by gotest.tools, a transitive
+// dependency; only define it if that ever goes away.
+func init() {
+	if flag.Lookup("update") == nil {
+		flag.Bool("update", false, "regenerate golden files in testdata/")
+	}
+}
+
+// updateGolden reports whether the test run was started with -update.
+func updateGolden() bool {
+	f := flag.Lookup("update")
+	return f != nil && f.Value.String() == "true"
+}
+
+func TestModifyLumeraGenesis(t *testing.T) {
+	t.Setenv("CLAIMS_STRICT", "true")
+	claimsCSV, err := readHostClaimsCSV()
+	require.NoError(t, err)
+
+	out, err := modifyLumeraGenesis(LumeraConfig, DefaultLumeraVersion, readTestGenesis(t), claimsCSV)
+	require.NoError(t, err)
+	g := decodeGenesis(t, out)
+
+	t.Run("Denoms", func(t *testing.T) {
+		require.Equal(t, "ulume", genesisValue(t, g, "app_state.staking.params.bond_denom"))
+		require.Equal(t, "ulume", genesisValue(t, g, "app_state.mint.params.mint_denom"))
+	})
+
+	t.Run("ICAHost", func(t *testing.T) {
+		require.Equal(t, true, genesisValue(t, g, "app_state.interchainaccounts.host_genesis_state.params.host_enabled"))
+		require.Equal(t, []interface{}{"*"}, genesisValue(t, g, "app_state.interchainaccounts.host_genesis_state.params.allow_messages"))
+	})
+
+	t.Run("RemovedModules", func(t *testing.T) {
+		appState := genesisValue(t, g, "app_state").(map[string]interface{})
+		require.NotContains(t, appState, "crisis")
+		require.NotContains(t, appState, "nft")
+	})
+
+	t.Run("ConsensusParams", func(t *testing.T) {
+		require.Equal(t, "22020096", genesisValue(t, g, "app_state.consensus.params.block.max_bytes"))
+		require.Equal(t, "-1", genesisValue(t, g, "app_state.consensus.params.block.max_gas"))
+		require.Equal(t, "100000", genesisValue(t, g, "app_state.consensus.params.evidence.max_age_num_blocks"))
+		require.Equal(t, "172800000000000", genesisValue(t, g, "app_state.consensus.params.evidence.max_age_duration"))
+		require.Equal(t, "1048576", genesisValue(t, g, "app_state.consensus.params.evidence.max_bytes"))
+		require.Equal(t, []interface{}{"ed25519"}, genesisValue(t, g, "app_state.consensus.params.validator.pub_key_types"))
+		require.Equal(t, "0", genesisValue(t, g, "app_state.consensus.params.version.app"))
+	})
+
+	t.Run("ClaimsTotal", func(t *testing.T) {
+		report, err := parseClaimsCSV(strings.NewReader(string(claimsCSV)), true)
+		require.NoError(t, err)
+		require.Equal(t, report.Total.String(), genesisValue(t, g, "app_state.claim.total_claimable_amount"))
+		require.Equal(t, claimsChecksum(claimsCSV), genesisValue(t, g, claimsChecksumKey))
+	})
+
+	t.Run("Untouched", func(t *testing.T) {
+		require.Equal(t, "2025-07-02T16:00:00Z", genesisValue(t, g, "genesis_time"))
+		require.Equal(t, "86400s", genesisValue(t, g, "app_state.action.params.expiration_duration"))
+	})
+}
+
+func TestLumeraOptionsGenesis(t *testing.T) {
+	t.Setenv("CLAIMS_STRICT", "true")
+	entries := GenerateClaims(100, 250)
+
+	config := GetLumeraChainConfig(DefaultLumeraVersion, false,
+		WithSupernodeMinimumStake(math.NewInt(1_000_000)),
+		WithActionExpiration(90*time.Second),
+		WithActionProcessingTime(5*time.Second, 2*time.Minute),
+		WithActionMaxPerBlock(3),
+		WithClaimsCSV(ClaimsCSV(entries)),
+		WithClaimEndTime(time.Hour),
+		WithMaxClaimsPerBlock(2),
+		WithGenesisKV("app_state.claim.params.enable_claims", false),
+		WithICAControllerEnabled(false),
+	)
+	out, err := config.ModifyGenesis(config, readTestGenesis(t))
+	require.NoError(t, err)
+	g := decodeGenesis(t, out)
+
+	require.Equal(t, "1000000", genesisValue(t, g, "app_state.supernode.params.minimum_stake_for_sn.amount"))
+	require.Equal(t, "90s", genesisValue(t, g, "app_state.action.params.expiration_duration"))
+	require.Equal(t, "5s", genesisValue(t, g, "app_state.action.params.min_processing_time"))
+	require.Equal(t, "120s", genesisValue(t, g, "app_state.action.params.max_processing_time"))
+	require.Equal(t, "3", genesisValue(t, g, "app_state.action.params.max_actions_per_block"))
+	require.Equal(t, "350", genesisValue(t, g, "app_state.claim.total_claimable_amount"))
+	require.Equal(t, claimsChecksum(ClaimsCSV(entries)), genesisValue(t, g, claimsChecksumKey))
+	// genesis_time is 2025-07-02T16:00:00Z.
+	require.Equal(t, "1751475600", genesisValue(t, g, "app_state.claim.params.claim_end_time"))
+	require.Equal(t, "2", genesisValue(t, g, "app_state.claim.params.max_claims_per_block"))
+	require.Equal(t, false, genesisValue(t, g, "app_state.claim.params.enable_claims"))
+	require.Equal(t, false, genesisValue(t, g, "app_state.interchainaccounts.controller_genesis_state.params.controller_enabled"))
+
+	require.Equal(t, []string{"--claims-path", "/var/cosmos-chain/lumera/config/claims.csv"}, config.AdditionalStartArgs)
+}
+
+func TestModifyLumeraGenesisMissingAppState(t *testing.T) {
+	_, err := modifyLumeraGenesis(LumeraConfig, DefaultLumeraVersion, []byte(`{"app_state":"oops"}`), nil)
+	require.Error(t, err)
+}
+
+// TestModifyLumeraGenesisGolden compares the modified genesis against the
+// committed snapshot. Regenerate it with:
+//
+//	go test -short -run TestModifyLumeraGenesisGolden -update
+func TestModifyLumeraGenesisGolden(t *testing.T) {
+	t.Setenv("CLAIMS_STRICT", "true")
+	claimsCSV, err := readHostClaimsCSV()
+	require.NoError(t, err)
+
+	got, err := modifyLumeraGenesis(LumeraConfig, DefaultLumeraVersion, readTestGenesis(t), claimsCSV)
+	require.NoError(t, err)
+
+	if updateGolden() {
+		require.NoError(t, os.MkdirAll(filepath.Dir(goldenGenesisPath), 0o755))
+		require.NoError(t, os.WriteFile(goldenGenesisPath, append(got, '\n'), 0o644))
+		t.Logf("updated %s", goldenGenesisPath)
+		return
+	}
+
+	want, err := os.ReadFile(goldenGenesisPath)
+	require.NoError(t, err, "missing golden file; run with -update")
+
+	if diff := diffJSON("", decodeGenesis(t, want), decodeGenesis(t, got)); len(diff) > 0 {
+		t.Fatalf("modified genesis differs from %s (run with -update to accept):\n%s",
+			goldenGenesisPath, strings.Join(diff, "\n"))
+	}
+}
+
+// diffJSON returns one line per differing JSON path between decoded values:
+// "- path: old" for removals, "+ path: new" for additions and
+// "~ path: old -> new" for changes.
+func diffJSON(path string, want, got interface{}) []string {
+	wantMap, wantIsMap := want.(map[string]interface{})
+	gotMap, gotIsMap := got.(map[string]interface{})
+	if wantIsMap && gotIsMap {
+		keys := make(map[string]struct{})
+		for k := range wantMap {
+			keys[k] = struct{}{}
+		}
+		for k := range gotMap {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var out []string
+		for _, k := range sorted {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			w, inWant := wantMap[k]
+			g, inGot := gotMap[k]
+			switch {
+			case !inGot:
+				out = append(out, fmt.Sprintf("- %s: %s", child, compactJSON(w)))
+			case !inWant:
+				out = append(out, fmt.Sprintf("+ %s: %s", child, compactJSON(g)))
+			default:
+				out = append(out, diffJSON(child, w, g)...)
+			}
+		}
+		return out
+	}
+
+	wantSlice, wantIsSlice := want.([]interface{})
+	gotSlice, gotIsSlice := got.([]interface{})
+	if wantIsSlice && gotIsSlice && len(wantSlice) == len(gotSlice) {
+		var out []string
+		for i := range wantSlice {
+			out = append(out, diffJSON(fmt.Sprintf("%s[%d]", path, i), wantSlice[i], gotSlice[i])...)
+		}
+		return out
+	}
+
+	if reflect.DeepEqual(want, got) {
+		return nil
+	}
+	return []string{fmt.Sprintf("~ %s: %s -> %s", path, compactJSON(want), compactJSON(got))}
+}
+
+// compactJSON renders v on one line, truncated for readability.
+func compactJSON(v interface{}) string {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	const max = 120
+	if len(bz) > max {
+		return string(bz[:max]) + "..."
+	}
+	return string(bz)
+}
+
+func TestDiffJSON(t *testing.T) {
+	want := decodeGenesis(t, []byte(`{"a":{"b":"1","c":[1,2]},"gone":true}`))
+	got := decodeGenesis(t, []byte(`{"a":{"b":"2","c":[1,3]},"new":null}`))
+	require.Equal(t, []string{
+		`~ a.b: "1" -> "2"`,
+		`~ a.c[1]: 2 -> 3`,
+		`- gone: true`,
+		`+ new: null`,
+	}, diffJSON("", want, got))
+}
+
+func readTestGenesis(t testing.TB) []byte {
+	t.Helper()
+	bz, err := os.ReadFile(testGenesisPath)
+	require.NoError(t, err)
+	return bz
+}
+
+func decodeGenesis(t testing.TB, bz []byte) map[string]interface{} {
+	t.Helper()
+	var g map[string]interface{}
+	require.NoError(t, json.Unmarshal(bz, &g))
+	return g
+}
+
+// genesisValue returns the value at a dot-separated path, failing the test
+// if any component is missing.
+func genesisValue(t testing.TB, g map[string]interface{}, path string) interface{} {
+	t.Helper()
+	var cur interface{} = g
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		require.True(t, ok, "%s: %q is not an object", path, key)
+		cur, ok = m[key]
+		require.True(t, ok, "%s: %q not found", path, key)
+	}
+	return cur
+}
+
+// FuzzModifyLumeraGenesis feeds arbitrary JSON through modifyLumeraGenesis:
+// it must never panic, and applying it to its own output must be a no-op.
+func FuzzModifyLumeraGenesis(f *testing.F) {
+	f.Add(readTestGenesis(f), []byte("PtguxBoV5apR1Jwjizh8NLm9sAQauFZ49aM,2084495476440\n"))
+	f.Add([]byte(`{}`), []byte(nil))
+	f.Add([]byte(`null`), []byte(nil))
+	f.Add([]byte(`{"app_state":null}`), []byte(nil))
+	f.Add([]byte(`{"app_state":{}}`), []byte("x,1\n"))
+	f.Add([]byte(`{"app_state":{"staking":"x","consensus":[],"claim":1}}`), []byte(nil))
+	f.Add([]byte(`{"app_state":{"interchainaccounts":{"host_genesis_state":null}}}`), []byte(nil))
+
+	f.Fuzz(func(t *testing.T, genesis, claimsCSV []byte) {
+		t.Setenv("CLAIMS_STRICT", "false")
+		once, err := modifyLumeraGenesis(LumeraConfig, DefaultLumeraVersion, genesis, claimsCSV)
+		if err != nil {
+			return
+		}
+		twice, err := modifyLumeraGenesis(LumeraConfig, DefaultLumeraVersion, once, claimsCSV)
+		require.NoError(t, err, "modified genesis must be accepted again")
+		got := decodeGenesis(t, once)
+		require.Empty(t, diffJSON("", got, decodeGenesis(t, twice)), "modifier is not idempotent")
+
+		report, err := parseClaimsCSV(bytes.NewReader(claimsCSV), false)
+		require.NoError(t, err)
+		if claimsCSV != nil && report.Total.Sign() != 0 {
+			require.Equal(t, report.Total.String(), genesisValue(t, got, "app_state.claim.total_claimable_amount"))
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// claims.go - Synthetic Pastel claims for exercising the Lumera claim module.
+// The shipped claims.csv only holds real Pastel addresses whose keys we don't
+// have; the keys generated here can sign MsgClaim for their own entries.
+package interchaintest_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	// pastelAddressPrefix is the version prefix of Pastel mainnet P2PKH
+	// addresses. It makes every encoded address start with "Pt".
+	pastelAddressPrefix = []byte{0x0c, 0xe3}
+	// pastelScriptAddressPrefix is the P2SH counterpart ("pt...").
+	pastelScriptAddressPrefix = []byte{0x1a, 0xf6}
+)
+
+// PastelKey is a synthetic Pastel keypair.
+type PastelKey struct {
+	priv *secp256k1.PrivKey
+}
+
+// GeneratePastelKey returns a new random Pastel keypair.
+func GeneratePastelKey() PastelKey {
+	return PastelKey{priv: secp256k1.GenPrivKey()}
+}
+
+// PubKeyHex returns the hex-encoded compressed public key, as sent in
+// MsgClaim.pub_key.
+func (k PastelKey) PubKeyHex() string {
+	return hex.EncodeToString(k.priv.PubKey().Bytes())
+}
+
+// Address returns the Pastel address of the key: base58check over the
+// version prefix and RIPEMD160(SHA256(pubkey)).
+func (k PastelKey) Address() string {
+	return pastelAddress(k.priv.PubKey().Address())
+}
+
+// SignClaim signs the MsgClaim verification message
+// "<old_address>.<pub_key>.<new_address>" and returns the hex signature.
+func (k PastelKey) SignClaim(newAddress string) (string, error) {
+	msg := k.Address() + "." + k.PubKeyHex() + "." + newAddress
+	sig, err := k.priv.Sign([]byte(msg))
+	if err != nil {
+		return "", fmt.Errorf("sign claim: %w", err)
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+// pastelAddress encodes a 20-byte public key hash as a Pastel address.
+func pastelAddress(hash160 []byte) string {
+	payload := append(append([]byte{}, pastelAddressPrefix...), hash160...)
+	return base58.Encode(append(payload, base58Checksum(payload)...))
+}
+
+// validatePastelAddress checks that addr is a base58check-encoded Pastel
+// mainnet P2PKH or P2SH address with a matching checksum.
+func validatePastelAddress(addr string) error {
+	raw, err := base58.Decode(addr)
+	if err != nil {
+		return fmt.Errorf("address %q is not base58: %w", addr, err)
+	}
+	if len(raw) != len(pastelAddressPrefix)+20+4 {
+		return fmt.Errorf("address %q has invalid length %d", addr, len(raw))
+	}
+	if !bytes.HasPrefix(raw, pastelAddressPrefix) && !bytes.HasPrefix(raw, pastelScriptAddressPrefix) {
+		return fmt.Errorf("address %q has invalid prefix %x", addr, raw[:len(pastelAddressPrefix)])
+	}
+	payload, checksum := raw[:len(raw)-4], raw[len(raw)-4:]
+	if !bytes.Equal(checksum, base58Checksum(payload)) {
+		return fmt.Errorf("address %q has invalid checksum", addr)
+	}
+	return nil
+}
+
+// base58Checksum is the first 4 bytes of SHA256(SHA256(payload)).
+func base58Checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// ClaimEntry is a claims.csv row together with the key that owns it.
+type ClaimEntry struct {
+	Key    PastelKey
+	Amount math.Int
+}
+
+// GenerateClaims returns one entry with a fresh key per amount (in ulume).
+func GenerateClaims(amounts ...int64) []ClaimEntry {
+	entries := make([]ClaimEntry, 0, len(amounts))
+	for _, amount := range amounts {
+		entries = append(entries, ClaimEntry{Key: GeneratePastelKey(), Amount: math.NewInt(amount)})
+	}
+	return entries
+}
+
+// ClaimsCSV renders entries in the claims.csv format: one "address,amount"
+// row per entry, no header.
+func ClaimsCSV(entries []ClaimEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%s,%s\n", e.Key.Address(), e.Amount)
+	}
+	return buf.Bytes()
+}
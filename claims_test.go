@@ -0,0 +1,445 @@
+// claims_test.go — Claim module e2e: Lumera starts with a synthetic
+// claims.csv whose Pastel keys the test holds, so MsgClaim can actually be
+// signed and executed.
+package interchaintest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/mr-tron/base58"
+
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLumeraClaim claims a synthetic Pastel entry to a Lumera account.
+func TestLumeraClaim(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping claim e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+
+	entries := GenerateClaims(1_000_000_000, 2_500_000_000, 7_000_000)
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithClaimsCSV(ClaimsCSV(entries)),
+		// The shipped claim_end_time is in the past.
+		WithClaimEndTime(24*time.Hour),
+	)
+
+	t.Logf("Testing claims on Lumera %s (local image: %v)", version, useLocal)
+
+	lumera := startLumera(t, ctx, lumeraConfig)
+	claimant := interchaintest.GetAndFundTestUsers(t, ctx, "claimant", math.NewInt(10_000_000_000), lumera)[0]
+	denom := lumera.Config().Denom
+
+	t.Run("RecordsLoaded", func(t *testing.T) {
+		for _, e := range entries {
+			rec := queryClaimRecord(t, ctx, lumera, e.Key.Address())
+			require.False(t, rec.Claimed, "%s should be unclaimed", e.Key.Address())
+			require.Equal(t, e.Amount.String(), rec.amount(denom).String())
+		}
+		require.Equal(t, genesisTotalClaimable(t, ctx, lumera).String(), claimableRemaining(t, ctx, lumera).String(),
+			"claim module should hold total_claimable_amount before any claim")
+	})
+
+	t.Run("Claim", func(t *testing.T) {
+		entry := entries[0]
+		before, err := lumera.GetBalance(ctx, claimant.FormattedAddress(), denom)
+		require.NoError(t, err)
+		remainingBefore := claimableRemaining(t, ctx, lumera)
+
+		sig, err := entry.Key.SignClaim(claimant.FormattedAddress())
+		require.NoError(t, err)
+		res := broadcastMsgs(t, ctx, lumera, claimant.KeyName(),
+			msgClaim(entry.Key.Address(), claimant.FormattedAddress(), entry.Key.PubKeyHex(), sig))
+		requireTxSuccess(t, res)
+
+		// Lumera's ante handler waives fees for claim txs, so that a fresh
+		// address can claim: the claimant gains exactly the claimed amount,
+		// although the tx offered a fee.
+		require.True(t, transferredAmount(t, res, claimant.FormattedAddress(), moduleAddress(t, lumera, authtypes.FeeCollectorName), denom).IsZero(),
+			"claim tx should not pay a fee")
+		after, err := lumera.GetBalance(ctx, claimant.FormattedAddress(), denom)
+		require.NoError(t, err)
+		require.Equal(t, before.Add(entry.Amount).String(), after.String(), "claimant should receive exactly the claimed amount")
+
+		rec := queryClaimRecord(t, ctx, lumera, entry.Key.Address())
+		require.True(t, rec.Claimed)
+		require.Equal(t, claimant.FormattedAddress(), rec.DestAddress)
+
+		require.Equal(t, remainingBefore.Sub(entry.Amount).String(), claimableRemaining(t, ctx, lumera).String(),
+			"total claimable amount should decrease by exactly the claimed amount")
+	})
+
+	t.Run("DoubleClaimRejected", func(t *testing.T) {
+		entry := entries[0]
+		sig, err := entry.Key.SignClaim(claimant.FormattedAddress())
+		require.NoError(t, err)
+		res := broadcastMsgs(t, ctx, lumera, claimant.KeyName(),
+			msgClaim(entry.Key.Address(), claimant.FormattedAddress(), entry.Key.PubKeyHex(), sig))
+		require.NotEqual(t, 0, res.Code, "an entry can only be claimed once")
+	})
+
+	// A key that does not own the Pastel address cannot claim it.
+	t.Run("ForeignKeyRejected", func(t *testing.T) {
+		victim, thief := entries[1], entries[2]
+		sig, err := thief.Key.SignClaim(claimant.FormattedAddress())
+		require.NoError(t, err)
+		res := broadcastMsgs(t, ctx, lumera, claimant.KeyName(),
+			msgClaim(victim.Key.Address(), claimant.FormattedAddress(), thief.Key.PubKeyHex(), sig))
+		require.NotEqual(t, 0, res.Code, "claim signed by a foreign key must fail")
+		require.False(t, queryClaimRecord(t, ctx, lumera, victim.Key.Address()).Claimed)
+	})
+}
+
+const (
+	// testMaxClaimsPerBlock lowers the shipped cap of 100.
+	testMaxClaimsPerBlock = 2
+	// testClaimWindow must leave room for chain startup and the
+	// max_claims_per_block checks before the window closes.
+	testClaimWindow = 3 * time.Minute
+)
+
+// TestLumeraClaimLimits checks max_claims_per_block and the claim window
+// (claim_end_time) against synthetic claims.
+func TestLumeraClaimLimits(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping claim limits e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+
+	entries := GenerateClaims(1_000_000, 2_000_000, 3_000_000, 4_000_000)
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithClaimsCSV(ClaimsCSV(entries)),
+		WithClaimEndTime(testClaimWindow),
+		WithMaxClaimsPerBlock(testMaxClaimsPerBlock),
+	)
+
+	t.Logf("Testing claim limits on Lumera %s (local image: %v)", version, useLocal)
+
+	lumera := startLumera(t, ctx, lumeraConfig)
+	claimant := interchaintest.GetAndFundTestUsers(t, ctx, "claimant", math.NewInt(10_000_000_000), lumera)[0]
+
+	params := queryClaimParams(t, ctx, lumera)
+	require.Equal(t, fmt.Sprint(testMaxClaimsPerBlock), params.MaxClaimsPerBlock)
+	endTime, err := strconv.ParseInt(params.ClaimEndTime, 10, 64)
+	require.NoError(t, err, "invalid claim_end_time %q", params.ClaimEndTime)
+	claimEnd := time.Unix(endTime, 0)
+	t.Logf("Claim window closes at %s", claimEnd.UTC())
+
+	claimMsgs := func(entries ...ClaimEntry) []map[string]interface{} {
+		msgs := make([]map[string]interface{}, 0, len(entries))
+		for _, e := range entries {
+			sig, err := e.Key.SignClaim(claimant.FormattedAddress())
+			require.NoError(t, err)
+			msgs = append(msgs, msgClaim(e.Key.Address(), claimant.FormattedAddress(), e.Key.PubKeyHex(), sig))
+		}
+		return msgs
+	}
+
+	// All messages of a tx execute in the same block.
+	t.Run("OverCapRejected", func(t *testing.T) {
+		require.True(t, time.Now().Before(claimEnd), "claim window closed before the test ran")
+		over := entries[:testMaxClaimsPerBlock+1]
+		res := broadcastMsgs(t, ctx, lumera, claimant.KeyName(), claimMsgs(over...)...)
+		require.NotEqual(t, 0, res.Code, "%d claims in one block must be rejected", len(over))
+		t.Logf("Over-cap claim tx rejected: %s", res.RawLog)
+		require.Contains(t, res.RawLog, fmt.Sprintf("message index: %d", testMaxClaimsPerBlock))
+		for _, e := range over {
+			require.False(t, queryClaimRecord(t, ctx, lumera, e.Key.Address()).Claimed, "rejected tx must not claim %s", e.Key.Address())
+		}
+	})
+
+	t.Run("AtCapAccepted", func(t *testing.T) {
+		require.True(t, time.Now().Before(claimEnd), "claim window closed before the test ran")
+		atCap := entries[:testMaxClaimsPerBlock]
+		res := broadcastMsgs(t, ctx, lumera, claimant.KeyName(), claimMsgs(atCap...)...)
+		requireTxSuccess(t, res)
+		for _, e := range atCap {
+			require.True(t, queryClaimRecord(t, ctx, lumera, e.Key.Address()).Claimed)
+		}
+	})
+
+	t.Run("AfterWindowRejected", func(t *testing.T) {
+		// Wait until block time has passed claim_end_time.
+		time.Sleep(time.Until(claimEnd))
+		require.NoError(t, testutil.WaitForBlocks(ctx, 2, lumera))
+
+		late := entries[len(entries)-1]
+		res := broadcastMsgs(t, ctx, lumera, claimant.KeyName(), claimMsgs(late)...)
+		require.NotEqual(t, 0, res.Code, "claim after claim_end_time must be rejected")
+		t.Logf("Late claim rejected: %s", res.RawLog)
+		require.False(t, queryClaimRecord(t, ctx, lumera, late.Key.Address()).Claimed)
+	})
+}
+
+// TestPastelAddressEncoding checks the synthetic addresses against the
+// encoding of the real ones in claims.csv.
+func TestPastelAddressEncoding(t *testing.T) {
+	const shipped = "PtguxBoV5apR1Jwjizh8NLm9sAQauFZ49aM" // first row of claims.csv
+	raw, err := base58.Decode(shipped)
+	require.NoError(t, err)
+	require.Len(t, raw, 26)
+	require.Equal(t, shipped, pastelAddress(raw[2:22]), "prefix + hash160 + checksum should round-trip")
+
+	key := GeneratePastelKey()
+	require.Len(t, key.Address(), len(shipped))
+	require.Equal(t, "Pt", key.Address()[:2])
+}
+
+// TestParseClaimsCSVStrict checks the strict claims.csv validation.
+func TestParseClaimsCSVStrict(t *testing.T) {
+	a, b := GeneratePastelKey().Address(), GeneratePastelKey().Address()
+	// Flip the last character to break the checksum.
+	last := a[len(a)-1]
+	flipped := "2"
+	if last == '2' {
+		flipped = "3"
+	}
+	badChecksum := a[:len(a)-1] + flipped
+
+	for _, tc := range []struct {
+		name    string
+		csv     string
+		wantErr string
+	}{
+		{name: "valid", csv: a + ",100\n" + b + ",250\n"},
+		{name: "empty", csv: "", wantErr: "no claim rows"},
+		{name: "missing amount", csv: a + "\n", wantErr: "line 1: want 2 fields"},
+		{name: "extra field", csv: a + ",1,2\n", wantErr: "line 1: want 2 fields"},
+		{name: "invalid amount", csv: a + ",1.5\n", wantErr: "line 1: invalid amount"},
+		{name: "negative amount", csv: a + ",-5\n", wantErr: "line 1: non-positive amount"},
+		{name: "zero amount", csv: a + ",100\n" + b + ",0\n", wantErr: "line 2: non-positive amount"},
+		{name: "bad checksum", csv: badChecksum + ",1\n", wantErr: "invalid checksum"},
+		{name: "not pastel", csv: "lumera1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq,1\n", wantErr: "line 1: address"},
+		{name: "duplicate", csv: a + ",1\n" + b + ",2\n" + a + ",3\n", wantErr: "line 3: duplicate address"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := parseClaimsCSV(strings.NewReader(tc.csv), true)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 2, report.Rows)
+			require.Equal(t, "350", report.Total.String())
+		})
+	}
+
+	// The allow-listed upstream zero row is accepted and reported.
+	var zero string
+	for addr := range claimsCSVZeroAmountAllowlist {
+		zero = addr
+	}
+	report, err := parseClaimsCSV(strings.NewReader(a+",100\n"+zero+",0\n"), true)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Rows)
+	require.Equal(t, 1, report.ZeroRows)
+	require.Equal(t, "2 rows (1 with zero amount), total 100", report.String())
+
+	// Lax mode keeps the historical behaviour: malformed rows are skipped.
+	report, err = parseClaimsCSV(strings.NewReader(a+",100\n"+b+",x\n"), false)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Rows)
+}
+
+// TestShippedClaimsCSVStrict checks that the checked-in claims.csv passes
+// strict validation and matches total_claimable_amount in genesis.json.
+func TestShippedClaimsCSVStrict(t *testing.T) {
+	f, err := os.Open(hostClaimsCSVPath())
+	require.NoError(t, err)
+	defer f.Close()
+
+	report, err := parseClaimsCSV(f, true)
+	require.NoError(t, err)
+	t.Logf("claims.csv: %s", report)
+	require.Equal(t, len(claimsCSVZeroAmountAllowlist), report.ZeroRows, "upstream claims.csv has one allow-listed zero-amount row")
+
+	g := decodeGenesis(t, readTestGenesis(t))
+	require.Equal(t, genesisValue(t, g, "app_state.claim.total_claimable_amount"), report.Total.String())
+}
+
+// FuzzParseClaimsCSV feeds arbitrary bytes through both parsing modes: neither
+// may panic, and input accepted in strict mode must parse identically in lax
+// mode.
+func FuzzParseClaimsCSV(f *testing.F) {
+	shipped, err := os.ReadFile(hostClaimsCSVPath())
+	require.NoError(f, err)
+	lines := strings.SplitAfter(string(shipped), "\n")
+	f.Add([]byte(strings.Join(lines[:1], "")))
+	f.Add([]byte(strings.Join(lines[:100], "")))
+	f.Add([]byte(""))
+	f.Add([]byte("address,amount\n"))
+	f.Add([]byte("\"PtguxBoV5apR1Jwjizh8NLm9sAQauFZ49aM\",\"5\"\r\n"))
+	f.Add([]byte("PtguxBoV5apR1Jwjizh8NLm9sAQauFZ49aM,-1\nx\n,,\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lax, err := parseClaimsCSV(bytes.NewReader(data), false)
+		require.NoError(t, err, "lax parsing never fails")
+		require.NotNil(t, lax.Total)
+
+		strict, err := parseClaimsCSV(bytes.NewReader(data), true)
+		if err != nil {
+			return
+		}
+		require.Positive(t, strict.Rows)
+		require.GreaterOrEqual(t, strict.Total.Sign(), 0)
+		require.Equal(t, strict.String(), lax.String(), "strict and lax disagree on valid input")
+	})
+}
+
+// FuzzClaimsCSVTotal renders fuzzed amounts as claims.csv rows and checks the
+// parsed total against a reference sum of the amounts.
+func FuzzClaimsCSVTotal(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 0})
+	f.Add(binary.BigEndian.AppendUint64(nil, 2084495476440))
+	f.Add(bytes.Repeat([]byte{0xff}, 8*4))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		const maxRows = 256
+		var buf bytes.Buffer
+		want := new(big.Int)
+		rows, zeros := 0, 0
+		for ; len(data) >= 8 && rows < maxRows; data = data[8:] {
+			amount := binary.BigEndian.Uint64(data)
+			hash := sha256.Sum256(binary.BigEndian.AppendUint64(nil, uint64(rows)))
+			fmt.Fprintf(&buf, "%s,%d\n", pastelAddress(hash[:20]), amount)
+			want.Add(want, new(big.Int).SetUint64(amount))
+			if amount == 0 {
+				zeros++
+			}
+			rows++
+		}
+
+		lax, err := parseClaimsCSV(bytes.NewReader(buf.Bytes()), false)
+		require.NoError(t, err)
+		require.Equal(t, rows, lax.Rows)
+		require.Equal(t, want.String(), lax.Total.String())
+
+		require.Equal(t, zeros, lax.ZeroRows)
+
+		strict, err := parseClaimsCSV(bytes.NewReader(buf.Bytes()), true)
+		if rows == 0 {
+			require.Error(t, err)
+			return
+		}
+		if zeros > 0 {
+			// Generated addresses are never on the zero-amount allow-list.
+			require.ErrorContains(t, err, "non-positive amount")
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, rows, strict.Rows)
+		require.Equal(t, want.String(), strict.Total.String())
+	})
+}
+
+// claimRecord is the subset of a claim record query response used by tests.
+type claimRecord struct {
+	OldAddress string `json:"old_address"`
+	Balance    []struct {
+		Denom  string `json:"denom"`
+		Amount string `json:"amount"`
+	} `json:"balance"`
+	Claimed     bool   `json:"claimed"`
+	DestAddress string `json:"dest_address"`
+}
+
+// amount returns the record balance in denom.
+func (r claimRecord) amount(denom string) math.Int {
+	for _, c := range r.Balance {
+		if c.Denom == denom {
+			amount, ok := math.NewIntFromString(c.Amount)
+			if ok {
+				return amount
+			}
+		}
+	}
+	return math.ZeroInt()
+}
+
+// claimParams is the subset of claim module params used by tests.
+type claimParams struct {
+	EnableClaims      bool   `json:"enable_claims"`
+	ClaimEndTime      string `json:"claim_end_time"`
+	MaxClaimsPerBlock string `json:"max_claims_per_block"`
+}
+
+func queryClaimParams(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) claimParams {
+	t.Helper()
+	var resp struct {
+		Params claimParams `json:"params"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "claim", "params"))
+	return resp.Params
+}
+
+func queryClaimRecord(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, oldAddress string) claimRecord {
+	t.Helper()
+	var resp struct {
+		Record claimRecord `json:"record"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "claim", "claim-record", oldAddress))
+	return resp.Record
+}
+
+// claimableRemaining returns the total claimable amount still unclaimed. The
+// claim module is funded with total_claimable_amount at genesis and pays
+// every claim from its account, so its balance is that total minus
+// everything claimed so far (RecordsLoaded checks the starting point).
+func claimableRemaining(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) math.Int {
+	t.Helper()
+	bal, err := lumera.GetBalance(ctx, moduleAddress(t, lumera, "claim"), lumera.Config().Denom)
+	require.NoError(t, err)
+	return bal
+}
+
+// genesisTotalClaimable returns total_claimable_amount from the node's
+// genesis file.
+func genesisTotalClaimable(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) math.Int {
+	t.Helper()
+	stdout, _, err := lumera.Exec(ctx, []string{"cat", lumera.HomeDir() + "/config/genesis.json"}, nil)
+	require.NoError(t, err)
+	var genesis struct {
+		AppState struct {
+			Claim struct {
+				TotalClaimableAmount string `json:"total_claimable_amount"`
+			} `json:"claim"`
+		} `json:"app_state"`
+	}
+	require.NoError(t, json.Unmarshal(stdout, &genesis))
+	total, ok := math.NewIntFromString(genesis.AppState.Claim.TotalClaimableAmount)
+	require.True(t, ok, "invalid total_claimable_amount %q", genesis.AppState.Claim.TotalClaimableAmount)
+	return total
+}
+
+func msgClaim(oldAddress, newAddress, pubKeyHex, signature string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":      "/lumera.claim.MsgClaim",
+		"oldAddress": oldAddress,
+		"newAddress": newAddress,
+		"pubKey":     pubKeyHex,
+		"signature":  signature,
+	}
+}
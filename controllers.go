@@ -0,0 +1,291 @@
+// controllers.go - Registry of chains that can act as ICA controller against
+// the Lumera host, so the ICA scenarios are not tied to Osmosis.
+package interchaintest_test
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+)
+
+// DefaultController is the controller chain used when ICA_CONTROLLER is not
+// set.
+const DefaultController = "osmosis"
+
+// DefaultMultiControllers are the controller chains connected to one Lumera
+// host when ICA_CONTROLLERS is not set.
+const DefaultMultiControllers = "osmosis,gaia"
+
+// DefaultFeeController is the controller chain for the ICS-29 fee tests when
+// ICA_CONTROLLER is not set. Osmosis has no fee middleware.
+const DefaultFeeController = "simd"
+
+// ControllerChain is a registry entry: a chain config template plus the image
+// versions known to work as ICA controller.
+type ControllerChain struct {
+	// Name is the registry key, also used as the chain's config name.
+	Name string
+	// Versions are the supported image tags; the first is the default.
+	Versions []string
+	// ICSConsumer marks chains that only run as an Interchain Security
+	// consumer and need a provider chain in the topology.
+	ICSConsumer bool
+	// FeeMiddleware marks chains whose ICA controller stack is wrapped in
+	// the ICS-29 fee middleware, so ICA packets can be incentivized.
+	FeeMiddleware bool
+
+	repository string
+	config     ibc.ChainConfig
+}
+
+// controllerChains is ordered by name, as returned by ControllerChainNames.
+var controllerChains = []ControllerChain{
+	{
+		Name:       "gaia",
+		Versions:   []string{"v18.1.0"},
+		repository: "ghcr.io/strangelove-ventures/heighliner/gaia",
+		config: ibc.ChainConfig{
+			Type:           "cosmos",
+			ChainID:        "gaia-test-1",
+			Bin:            "gaiad",
+			Bech32Prefix:   "cosmos",
+			Denom:          "uatom",
+			GasPrices:      "0.01uatom",
+			GasAdjustment:  2.0,
+			TrustingPeriod: "336h",
+		},
+	},
+	{
+		Name:        "neutron",
+		Versions:    []string{"v3.0.5"},
+		ICSConsumer: true,
+		repository:  "ghcr.io/strangelove-ventures/heighliner/neutron",
+		config: ibc.ChainConfig{
+			Type:           "cosmos",
+			ChainID:        "neutron-test-1",
+			Bin:            "neutrond",
+			Bech32Prefix:   "neutron",
+			Denom:          "untrn",
+			GasPrices:      "0.01untrn",
+			GasAdjustment:  2.0,
+			TrustingPeriod: "336h",
+		},
+	},
+	{
+		Name:       "osmosis",
+		Versions:   []string{"v25.0.0"},
+		repository: "ghcr.io/strangelove-ventures/heighliner/osmosis",
+		config: ibc.ChainConfig{
+			Type:           "cosmos",
+			ChainID:        "osmosis-test-1",
+			Bin:            "osmosisd",
+			Bech32Prefix:   "osmo",
+			Denom:          "uosmo",
+			GasPrices:      "0.025uosmo",
+			GasAdjustment:  1.5,
+			TrustingPeriod: "336h",
+		},
+	},
+	{
+		// ibc-go's simapp wires the ICA controller module, behind the fee
+		// middleware.
+		Name:          "simd",
+		Versions:      []string{"v8.5.1"},
+		FeeMiddleware: true,
+		repository:    "ghcr.io/strangelove-ventures/heighliner/ibc-go-simd",
+		config: ibc.ChainConfig{
+			Type:           "cosmos",
+			ChainID:        "simd-test-1",
+			Bin:            "simd",
+			Bech32Prefix:   "cosmos",
+			Denom:          "photon",
+			GasPrices:      "0.0photon",
+			GasAdjustment:  1.5,
+			TrustingPeriod: "336h",
+		},
+	},
+}
+
+// ControllerChainNames returns the names of all registered controller chains.
+func ControllerChainNames() []string {
+	names := make([]string, 0, len(controllerChains))
+	for _, c := range controllerChains {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// GetControllerChain returns the registry entry for name.
+func GetControllerChain(name string) (ControllerChain, error) {
+	for _, c := range controllerChains {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return ControllerChain{}, fmt.Errorf("unknown controller chain %q (known: %v)", name, ControllerChainNames())
+}
+
+// DefaultVersion returns the image tag used when none is requested.
+func (c ControllerChain) DefaultVersion() string {
+	return c.Versions[0]
+}
+
+// ChainConfig returns the chain config running image tag version, or the
+// default version if empty. Tags outside Versions are allowed, to try new
+// releases, but aren't known to work.
+func (c ControllerChain) ChainConfig(version string) ibc.ChainConfig {
+	if version == "" {
+		version = c.DefaultVersion()
+	}
+	config := c.config
+	config.Name = c.Name
+	config.Images = []ibc.DockerImage{{
+		Repository: c.repository,
+		Version:    version,
+		UIDGID:     "1025:1025",
+	}}
+	return config
+}
+
+// Supports reports whether version is one of the known-good tags.
+func (c ControllerChain) Supports(version string) bool {
+	return slices.Contains(c.Versions, version)
+}
+
+// GetControllerChainConfig looks up a controller chain by name and returns
+// its config at version ("" for the default).
+func GetControllerChainConfig(name, version string) (ibc.ChainConfig, error) {
+	c, err := GetControllerChain(name)
+	if err != nil {
+		return ibc.ChainConfig{}, err
+	}
+	return c.ChainConfig(version), nil
+}
+
+// controllerFromEnv returns the controller chain selected by ICA_CONTROLLER
+// and the image versions to run it at: ICA_CONTROLLER_VERSIONS
+// (comma-separated), OSMOSIS_VERSIONS for Osmosis, or its default version.
+func controllerFromEnv() (ControllerChain, []string, error) {
+	name := os.Getenv("ICA_CONTROLLER")
+	if name == "" {
+		name = DefaultController
+	}
+	c, err := GetControllerChain(name)
+	if err != nil {
+		return ControllerChain{}, nil, fmt.Errorf("ICA_CONTROLLER: %w", err)
+	}
+	versions := splitVersions(os.Getenv("ICA_CONTROLLER_VERSIONS"))
+	if len(versions) == 0 && c.Name == "osmosis" {
+		versions = splitVersions(os.Getenv("OSMOSIS_VERSIONS"))
+	}
+	if len(versions) == 0 {
+		versions = []string{c.DefaultVersion()}
+	}
+	return c, versions, nil
+}
+
+// osmosisFromEnv returns the Osmosis registry entry and the version for
+// Osmosis-specific tests: the first of OSMOSIS_VERSIONS, or its default.
+func osmosisFromEnv() (ControllerChain, string, error) {
+	c, err := GetControllerChain("osmosis")
+	if err != nil {
+		return ControllerChain{}, "", err
+	}
+	if versions := splitVersions(os.Getenv("OSMOSIS_VERSIONS")); len(versions) > 0 {
+		return c, versions[0], nil
+	}
+	return c, c.DefaultVersion(), nil
+}
+
+// feeControllerFromEnv returns the controller chain for the ICS-29 fee tests:
+// ICA_CONTROLLER if set, which must have the fee middleware, else
+// DefaultFeeController. The version is the first of ICA_CONTROLLER_VERSIONS,
+// or its default.
+func feeControllerFromEnv() (ControllerChain, string, error) {
+	name := os.Getenv("ICA_CONTROLLER")
+	if name == "" {
+		name = DefaultFeeController
+	}
+	c, err := GetControllerChain(name)
+	if err != nil {
+		return ControllerChain{}, "", fmt.Errorf("ICA_CONTROLLER: %w", err)
+	}
+	if !c.FeeMiddleware {
+		return ControllerChain{}, "", fmt.Errorf("ICA_CONTROLLER: %s has no ICS-29 fee middleware on its ICA controller stack", name)
+	}
+	if versions := splitVersions(os.Getenv("ICA_CONTROLLER_VERSIONS")); len(versions) > 0 {
+		return c, versions[0], nil
+	}
+	return c, c.DefaultVersion(), nil
+}
+
+// controllerSpec selects a registered controller chain at an image version.
+type controllerSpec struct {
+	chain   ControllerChain
+	version string
+	// genesisKVs are applied to the chain's genesis, e.g. to enable its ICA
+	// host for Lumera-controlled accounts.
+	genesisKVs []cosmos.GenesisKV
+}
+
+// String returns the spec as "name=version".
+func (s controllerSpec) String() string {
+	return s.chain.Name + "=" + s.version
+}
+
+// parseControllerSpecs parses a comma-separated list of controller chains,
+// each "name" (default version) or "name=version". Every chain may appear
+// once, since chain IDs come from the registry.
+func parseControllerSpecs(s string) ([]controllerSpec, error) {
+	var specs []controllerSpec
+	seen := make(map[string]bool)
+	for _, entry := range splitVersions(s) {
+		name, version, _ := strings.Cut(entry, "=")
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		c, err := GetControllerChain(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("controller chain %q listed twice", name)
+		}
+		seen[name] = true
+		if version == "" {
+			version = c.DefaultVersion()
+		}
+		specs = append(specs, controllerSpec{chain: c, version: version})
+	}
+	return specs, nil
+}
+
+// multiControllersFromEnv returns the controller chains listed in
+// ICA_CONTROLLERS (see parseControllerSpecs), or DefaultMultiControllers.
+func multiControllersFromEnv() ([]controllerSpec, error) {
+	list := os.Getenv("ICA_CONTROLLERS")
+	if list == "" {
+		list = DefaultMultiControllers
+	}
+	specs, err := parseControllerSpecs(list)
+	if err != nil {
+		return nil, fmt.Errorf("ICA_CONTROLLERS: %w", err)
+	}
+	if len(specs) < 2 {
+		return nil, fmt.Errorf("ICA_CONTROLLERS: need at least two controller chains, got %q", list)
+	}
+	return specs, nil
+}
+
+// splitVersions splits a comma-separated version list, dropping blanks.
+func splitVersions(s string) []string {
+	var versions []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,150 @@
+// controllers_test.go - Offline tests of the controller chain registry.
+package interchaintest_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestControllerChainRegistry(t *testing.T) {
+	names := ControllerChainNames()
+	require.Equal(t, []string{"gaia", "neutron", "osmosis", "simd"}, names)
+	require.True(t, sort.StringsAreSorted(names))
+
+	// Neutron stays listed but is skipped until a provider chain is wired in.
+	neutron, err := GetControllerChain("neutron")
+	require.NoError(t, err)
+	require.True(t, neutron.ICSConsumer)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			c, err := GetControllerChain(name)
+			require.NoError(t, err)
+			require.NotEmpty(t, c.Versions)
+			require.True(t, c.Supports(c.DefaultVersion()))
+
+			config, err := GetControllerChainConfig(name, "")
+			require.NoError(t, err)
+			require.Equal(t, name, config.Name)
+			require.NotEqual(t, LumeraConfig.ChainID, config.ChainID)
+			require.NotEmpty(t, config.Bin)
+			require.NotEmpty(t, config.Bech32Prefix)
+			require.Contains(t, config.GasPrices, config.Denom)
+			require.Len(t, config.Images, 1)
+			require.Equal(t, c.DefaultVersion(), config.Images[0].Version)
+		})
+	}
+
+	config, err := GetControllerChainConfig("osmosis", "v26.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "ghcr.io/strangelove-ventures/heighliner/osmosis", config.Images[0].Repository)
+	require.Equal(t, "v26.0.0", config.Images[0].Version)
+
+	// Configs are copies; changing one must not leak into the registry.
+	config.Images[0].Version = "mutated"
+	config, err = GetControllerChainConfig("osmosis", "")
+	require.NoError(t, err)
+	require.Equal(t, "v25.0.0", config.Images[0].Version)
+
+	_, err = GetControllerChainConfig("juno", "")
+	require.ErrorContains(t, err, `unknown controller chain "juno"`)
+}
+
+func TestControllerFromEnv(t *testing.T) {
+	t.Setenv("ICA_CONTROLLER", "")
+	t.Setenv("ICA_CONTROLLER_VERSIONS", "")
+	t.Setenv("OSMOSIS_VERSIONS", "")
+	c, versions, err := controllerFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, DefaultController, c.Name)
+	require.Equal(t, []string{c.DefaultVersion()}, versions)
+
+	t.Setenv("OSMOSIS_VERSIONS", "v25.0.0,v26.0.0")
+	_, versions, err = controllerFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, []string{"v25.0.0", "v26.0.0"}, versions)
+
+	// OSMOSIS_VERSIONS only applies to Osmosis.
+	t.Setenv("ICA_CONTROLLER", "gaia")
+	c, versions, err = controllerFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "gaia", c.Name)
+	require.Equal(t, []string{"v18.1.0"}, versions)
+
+	t.Setenv("ICA_CONTROLLER_VERSIONS", "v19.0.0")
+	_, versions, err = controllerFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, []string{"v19.0.0"}, versions)
+
+	t.Setenv("ICA_CONTROLLER", "nope")
+	_, _, err = controllerFromEnv()
+	require.ErrorContains(t, err, "ICA_CONTROLLER")
+}
+
+func TestOsmosisFromEnv(t *testing.T) {
+	t.Setenv("ICA_CONTROLLER", "gaia")
+	t.Setenv("OSMOSIS_VERSIONS", "")
+	c, version, err := osmosisFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "osmosis", c.Name)
+	require.Equal(t, c.DefaultVersion(), version)
+
+	t.Setenv("OSMOSIS_VERSIONS", "v26.0.0,v25.0.0")
+	_, version, err = osmosisFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "v26.0.0", version)
+}
+
+func TestFeeControllerFromEnv(t *testing.T) {
+	t.Setenv("ICA_CONTROLLER", "")
+	t.Setenv("ICA_CONTROLLER_VERSIONS", "")
+	c, version, err := feeControllerFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, DefaultFeeController, c.Name)
+	require.True(t, c.FeeMiddleware)
+	require.Equal(t, c.DefaultVersion(), version)
+
+	t.Setenv("ICA_CONTROLLER_VERSIONS", "v8.6.0,v8.5.1")
+	_, version, err = feeControllerFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "v8.6.0", version)
+
+	t.Setenv("ICA_CONTROLLER", "osmosis")
+	_, _, err = feeControllerFromEnv()
+	require.ErrorContains(t, err, "no ICS-29 fee middleware")
+}
+
+func TestMultiControllersFromEnv(t *testing.T) {
+	t.Setenv("ICA_CONTROLLERS", "")
+	specs, err := multiControllersFromEnv()
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	require.Equal(t, "osmosis", specs[0].chain.Name)
+	require.Equal(t, "v25.0.0", specs[0].version)
+	require.Equal(t, "gaia", specs[1].chain.Name)
+	require.Equal(t, "v18.1.0", specs[1].version)
+
+	t.Setenv("ICA_CONTROLLERS", "simd, gaia=v19.0.0 ,")
+	specs, err = multiControllersFromEnv()
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	require.Equal(t, "simd", specs[0].chain.Name)
+	require.Equal(t, "v8.5.1", specs[0].version)
+	require.Equal(t, "gaia", specs[1].chain.Name)
+	require.Equal(t, "v19.0.0", specs[1].version)
+	require.Equal(t, "[simd=v8.5.1 gaia=v19.0.0]", fmt.Sprint(specs))
+
+	for list, want := range map[string]string{
+		"osmosis":             "at least two",
+		"osmosis,osmosis=v26": `"osmosis" listed twice`,
+		"osmosis,juno":        `unknown controller chain "juno"`,
+	} {
+		t.Setenv("ICA_CONTROLLERS", list)
+		_, err := multiControllersFromEnv()
+		require.ErrorContains(t, err, "ICA_CONTROLLERS", list)
+		require.ErrorContains(t, err, want, list)
+	}
+}
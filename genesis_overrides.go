@@ -0,0 +1,137 @@
+// genesis_overrides.go - Declarative genesis overrides loaded from a YAML or
+// JSON file, so parameter combinations can be tried without editing Go.
+//
+// The file is a list of operations applied in order, e.g. in JSON form:
+//
+//	[
+//	  {"op": "set", "path": "app_state.gov.params.voting_period", "value": "30s"},
+//	  {"op": "delete", "path": "app_state.crisis"}
+//	]
+//
+// Paths are dot-separated like WithGenesisKV; numeric components index into
+// arrays. As with WithGenesisKV, set only adds the last path component, so a
+// mistyped parent fails instead of creating a new subtree.
+//
+// JSON values are applied byte-for-byte. YAML goes through a YAML→JSON
+// conversion that turns integers wider than 64 bits into float64, so quote
+// big numbers in YAML files (genesis stores amounts as strings anyway).
+package interchaintest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/icza/dyno"
+	"sigs.k8s.io/yaml"
+)
+
+// genesisOverridesEnv names the overrides file applied to every Lumera chain
+// config that doesn't set one via WithGenesisOverrides.
+const genesisOverridesEnv = "GENESIS_OVERRIDES"
+
+const (
+	overrideOpSet    = "set"
+	overrideOpDelete = "delete"
+)
+
+// GenesisOverride is a single operation of a genesis overrides file.
+type GenesisOverride struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	// Value is kept as raw JSON so large integers in JSON files survive
+	// unrounded. In YAML they must be quoted; see the file comment.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// WithGenesisOverrides applies the overrides file at path after all other
+// genesis modifications. It takes precedence over GENESIS_OVERRIDES; an empty
+// path disables the env var.
+func WithGenesisOverrides(path string) LumeraOption {
+	return func(o *lumeraOptions) {
+		o.overridesPath = path
+	}
+}
+
+// genesisOverridesPathFromEnv returns the GENESIS_OVERRIDES file, if any.
+func genesisOverridesPathFromEnv() string {
+	return os.Getenv(genesisOverridesEnv)
+}
+
+// readGenesisOverrides loads and validates an overrides file. JSON files are
+// decoded directly to keep values exact; anything else is read as YAML.
+func readGenesisOverrides(path string) ([]GenesisOverride, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read genesis overrides: %w", err)
+	}
+	var overrides []GenesisOverride
+	if json.Valid(bz) {
+		dec := json.NewDecoder(bytes.NewReader(bz))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&overrides)
+	} else {
+		err = yaml.UnmarshalStrict(bz, &overrides)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse genesis overrides %s: %w", path, err)
+	}
+	for i, ov := range overrides {
+		if ov.Path == "" {
+			return nil, fmt.Errorf("genesis overrides %s: entry %d has no path", path, i)
+		}
+		switch ov.Op {
+		case overrideOpSet:
+			if ov.Value == nil {
+				return nil, fmt.Errorf("genesis overrides %s: entry %d (%s) has no value", path, i, ov.Path)
+			}
+		case overrideOpDelete:
+		default:
+			return nil, fmt.Errorf("genesis overrides %s: entry %d (%s) has unknown op %q", path, i, ov.Path, ov.Op)
+		}
+	}
+	return overrides, nil
+}
+
+// applyGenesisOverrides applies overrides to genesis in order.
+func applyGenesisOverrides(genesis []byte, overrides []GenesisOverride) ([]byte, error) {
+	g := make(map[string]interface{})
+	if err := json.Unmarshal(genesis, &g); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal genesis: %w", err)
+	}
+
+	for _, ov := range overrides {
+		path := overridePath(ov.Path)
+		var err error
+		switch ov.Op {
+		case overrideOpSet:
+			err = dyno.Set(g, ov.Value, path...)
+		case overrideOpDelete:
+			err = dyno.Delete(g, path[len(path)-1], path[:len(path)-1]...)
+		default:
+			err = fmt.Errorf("unknown op %q", ov.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("genesis override %s %s: %w", ov.Op, ov.Path, err)
+		}
+	}
+
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// overridePath splits a dot-separated path into dyno path components.
+func overridePath(p string) []interface{} {
+	parts := strings.Split(p, ".")
+	path := make([]interface{}, len(parts))
+	for i, part := range parts {
+		if n, err := strconv.Atoi(part); err == nil {
+			path[i] = n
+		} else {
+			path[i] = part
+		}
+	}
+	return path
+}
@@ -0,0 +1,162 @@
+// genesis_overrides_test.go - Offline tests of genesis overrides files.
+package interchaintest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exampleOverridesPath = "testdata/genesis-overrides.example.yaml"
+
+func TestGenesisOverridesExample(t *testing.T) {
+	t.Setenv(genesisOverridesEnv, exampleOverridesPath)
+	config := GetLumeraChainConfig(DefaultLumeraVersion, false)
+
+	out, err := config.ModifyGenesis(config, readTestGenesis(t))
+	require.NoError(t, err)
+	g := decodeGenesis(t, out)
+
+	require.Equal(t, "1000", genesisValue(t, g, "app_state.action.params.base_action_fee.amount"))
+	require.Equal(t, "1", genesisValue(t, g, "app_state.action.params.fee_per_kbyte.amount"))
+	require.Equal(t, "1000000", genesisValue(t, g, "app_state.supernode.params.minimum_stake_for_sn.amount"))
+	require.Equal(t, "30s", genesisValue(t, g, "app_state.gov.params.voting_period"))
+	require.Equal(t, "15s", genesisValue(t, g, "app_state.gov.params.expedited_voting_period"))
+	require.NotContains(t, genesisValue(t, g, "app_state.supernode.params"), "metrics_thresholds")
+
+	// Built-in modifications still apply.
+	require.Equal(t, "ulume", genesisValue(t, g, "app_state.staking.params.bond_denom"))
+}
+
+func TestGenesisOverridesOptionPrecedence(t *testing.T) {
+	t.Setenv(genesisOverridesEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	// The option replaces the env var; an empty path disables overrides.
+	config := GetLumeraChainConfig(DefaultLumeraVersion, false, WithGenesisOverrides(""))
+	_, err := config.ModifyGenesis(config, readTestGenesis(t))
+	require.NoError(t, err)
+
+	config = GetLumeraChainConfig(DefaultLumeraVersion, false)
+	_, err = config.ModifyGenesis(config, readTestGenesis(t))
+	require.ErrorContains(t, err, "read genesis overrides")
+
+	// Overrides run after WithGenesisKV.
+	config = GetLumeraChainConfig(DefaultLumeraVersion, false,
+		WithGenesisKV("app_state.gov.params.voting_period", "60s"),
+		WithGenesisOverrides(exampleOverridesPath),
+	)
+	out, err := config.ModifyGenesis(config, readTestGenesis(t))
+	require.NoError(t, err)
+	require.Equal(t, "30s", genesisValue(t, decodeGenesis(t, out), "app_state.gov.params.voting_period"))
+}
+
+func TestApplyGenesisOverrides(t *testing.T) {
+	genesis := []byte(`{"app_state":{"a":{"b":"1","list":["x","y","z"]},"c":{}}}`)
+
+	tests := []struct {
+		name    string
+		file    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "json set and delete",
+			file: `[{"op":"set","path":"app_state.a.b","value":"2"},{"op":"delete","path":"app_state.c"}]`,
+			want: `{"app_state":{"a":{"b":"2","list":["x","y","z"]}}}`,
+		},
+		{
+			name: "yaml adds key to existing map",
+			file: "- op: set\n  path: app_state.c.param\n  value: {amount: \"5\", denom: ulume}\n",
+			want: `{"app_state":{"a":{"b":"1","list":["x","y","z"]},"c":{"param":{"amount":"5","denom":"ulume"}}}}`,
+		},
+		{
+			name: "array index",
+			file: "- op: set\n  path: app_state.a.list.1\n  value: w\n- op: delete\n  path: app_state.a.list.0\n",
+			want: `{"app_state":{"a":{"b":"1","list":["w","z"]},"c":{}}}`,
+		},
+		{
+			name: "delete missing key is a no-op",
+			file: "- op: delete\n  path: app_state.nope\n",
+			want: string(genesis),
+		},
+		{
+			name:    "unknown op",
+			file:    "- op: add\n  path: app_state.a\n  value: 1\n",
+			wantErr: `unknown op "add"`,
+		},
+		{
+			name:    "set without value",
+			file:    "- op: set\n  path: app_state.a\n",
+			wantErr: "has no value",
+		},
+		{
+			name:    "missing path",
+			file:    "- op: delete\n",
+			wantErr: "has no path",
+		},
+		{
+			name:    "unknown field",
+			file:    "- op: set\n  key: app_state.a\n  value: 1\n",
+			wantErr: "unknown field",
+		},
+		{
+			name:    "missing parent",
+			file:    "- op: set\n  path: app_state.typo.param\n  value: 1\n",
+			wantErr: "missing key: typo",
+		},
+		{
+			name:    "path through a scalar",
+			file:    "- op: set\n  path: app_state.a.b.c\n  value: 1\n",
+			wantErr: "genesis override set app_state.a.b.c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "overrides.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.file), 0o600))
+
+			overrides, err := readGenesisOverrides(path)
+			if err == nil {
+				var out []byte
+				out, err = applyGenesisOverrides(genesis, overrides)
+				if tt.wantErr == "" {
+					require.NoError(t, err)
+					require.JSONEq(t, tt.want, string(out))
+					return
+				}
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestGenesisOverridesLargeIntegers pins which integers survive exactly:
+// JSONEq compares numbers as float64, so the output is checked textually.
+func TestGenesisOverridesLargeIntegers(t *testing.T) {
+	genesis := []byte(`{"app_state":{"a":{"b":"1"}}}`)
+	const big = "18751634842166000001" // wider than uint64
+
+	for _, tt := range []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "json", file: `[{"op":"set","path":"app_state.a.b","value":` + big + `}]`, want: `"b": ` + big},
+		{name: "quoted yaml", file: "- op: set\n  path: app_state.a.b\n  value: \"" + big + "\"\n", want: `"b": "` + big + `"`},
+		{name: "unquoted yaml is rounded", file: "- op: set\n  path: app_state.a.b\n  value: " + big + "\n", want: `"b": 18751634842166000000`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "overrides")
+			require.NoError(t, os.WriteFile(path, []byte(tt.file), 0o600))
+
+			overrides, err := readGenesisOverrides(path)
+			require.NoError(t, err)
+			out, err := applyGenesisOverrides(genesis, overrides)
+			require.NoError(t, err)
+			require.Contains(t, string(out), tt.want)
+		})
+	}
+}
@@ -2,16 +2,13 @@
 package interchaintest_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"os"
 	"testing"
 
-	"github.com/strangelove-ventures/interchaintest/v8"
 	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
-	"github.com/strangelove-ventures/interchaintest/v8/testreporter"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/zap/zaptest"
 )
 
 // TestLumeraGenesisSetup tests that Lumera starts correctly with modified genesis
@@ -21,51 +18,24 @@ func TestLumeraGenesisSetup(t *testing.T) {
 		t.Skip("skipping genesis setup test in short mode")
 	}
 
-	version := DefaultLumeraVersion
-	if v := os.Getenv("LUMERA_VERSION"); v != "" {
-		version = v
-	}
-	useLocal := os.Getenv("USE_LOCAL_IMAGE") == "true"
+	versions, useLocal := lumeraVersionsFromEnv(t)
 
-	t.Run("Genesis_"+version, func(t *testing.T) {
-		testGenesisSetup(t, version, useLocal)
-	})
+	matrix := newCompatMatrix(t, "Lumera genesis")
+	for _, version := range versions {
+		matrix.run(t, compatPair{Lumera: version}, func(t *testing.T) {
+			testGenesisSetup(t, version, useLocal)
+		})
+	}
 }
 
 func testGenesisSetup(t *testing.T, version string, useLocalImage bool) {
 	ctx := context.Background()
-	rep := testreporter.NewNopReporter()
-	eRep := rep.RelayerExecReporter(t)
-
-	client, network := interchaintest.DockerSetup(t)
 
 	config := GetLumeraChainConfig(version, useLocalImage)
 
 	t.Logf("Testing Lumera %s (local=%v)", version, useLocalImage)
 
-	// Build single chain
-	cf := interchaintest.NewBuiltinChainFactory(zaptest.NewLogger(t), []*interchaintest.ChainSpec{
-		{
-			ChainConfig:   config,
-			NumValidators: &[]int{1}[0],
-			NumFullNodes:  &[]int{0}[0],
-		},
-	})
-
-	chains, err := cf.Chains(t.Name())
-	require.NoError(t, err)
-
-	lumera := chains[0].(*cosmos.CosmosChain)
-
-	// Create interchain with just Lumera
-	ic := interchaintest.NewInterchain().AddChain(lumera)
-
-	require.NoError(t, ic.Build(ctx, eRep, interchaintest.InterchainBuildOptions{
-		TestName:  t.Name(),
-		Client:    client,
-		NetworkID: network,
-	}))
-	t.Cleanup(func() { _ = ic.Close() })
+	lumera := startLumera(t, ctx, config)
 
 	// Verify chain started successfully
 	height, err := lumera.Height(ctx)
@@ -144,24 +114,51 @@ func verifyGenesisModifications(t *testing.T, ctx context.Context, lumera *cosmo
 	})
 }
 
+// verifyClaimsCSV checks that the claims.csv the node was started with
+// (--claims-path) is the file total_claimable_amount was computed from: its
+// SHA-256 must match the checksum recorded in genesis and its total the
+// genesis total. In strict mode a missing file or checksum fails the test.
 func verifyClaimsCSV(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) {
-	// Check if claims.csv exists in the config directory
-	checkCmd := []string{
-		"test", "-f", lumera.HomeDir() + "/config/claims.csv",
-	}
-	_, _, err := lumera.Exec(ctx, checkCmd, nil)
+	strict := claimsStrictFromEnv()
+	claimsPath := claimsPathFromArgs(lumera.Config().AdditionalStartArgs)
+	require.NotEmpty(t, claimsPath, "--claims-path not set in start args")
 
+	stdout, _, err := lumera.Exec(ctx, []string{"cat", claimsPath}, nil)
 	if err != nil {
-		t.Logf("claims.csv not found in config directory (this may be expected)")
-		// Don't fail the test as claims.csv might not be required for all test scenarios
-	} else {
-		t.Logf("claims.csv present in config directory")
-
-		// Count lines in claims.csv
-		countCmd := []string{"wc", "-l", lumera.HomeDir() + "/config/claims.csv"}
-		stdout, _, err := lumera.Exec(ctx, countCmd, nil)
-		if err == nil {
-			t.Logf("   claims.csv has %s", string(stdout))
+		require.False(t, strict, "claims.csv not found at %s: %v", claimsPath, err)
+		t.Logf("claims.csv not found at %s (this may be expected)", claimsPath)
+		return
+	}
+	report, err := parseClaimsCSV(bytes.NewReader(stdout), strict)
+	require.NoError(t, err, "node claims.csv at %s is invalid", claimsPath)
+	t.Logf("   node claims.csv (%s): %s", claimsPath, report)
+
+	genesisJSON, _, err := lumera.Exec(ctx, []string{"cat", lumera.HomeDir() + "/config/genesis.json"}, nil)
+	require.NoError(t, err)
+	var genesis map[string]interface{}
+	require.NoError(t, json.Unmarshal(genesisJSON, &genesis))
+
+	recorded, _ := genesis[claimsChecksumKey].(string)
+	if recorded == "" {
+		require.False(t, strict, "genesis has no %s", claimsChecksumKey)
+		t.Logf("genesis has no %s, skipping checksum comparison", claimsChecksumKey)
+		return
+	}
+	require.Equal(t, recorded, claimsChecksum(stdout),
+		"node claims.csv differs from the file total_claimable_amount was computed from")
+
+	appState := genesis["app_state"].(map[string]interface{})
+	claim := appState["claim"].(map[string]interface{})
+	require.Equal(t, report.Total.String(), claim["total_claimable_amount"])
+	t.Logf("   claims.csv sha256 %s matches genesis", recorded)
+}
+
+// claimsPathFromArgs returns the value of --claims-path in start args.
+func claimsPathFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--claims-path" && i+1 < len(args) {
+			return args[i+1]
 		}
 	}
+	return ""
 }
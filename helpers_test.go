@@ -0,0 +1,227 @@
+// helpers_test.go - Shared helpers for Lumera e2e scenarios: chain setup,
+// signing and broadcasting arbitrary messages, and JSON queries.
+package interchaintest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/strangelove-ventures/interchaintest/v8/testreporter"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// defaultTxGas is the gas limit used for txs broadcast via broadcastMsgs.
+// Lumera messages are cheap; 2M leaves plenty of headroom for batches.
+const defaultTxGas = 2_000_000
+
+// lumeraVersionFromEnv returns the Lumera version and local-image flag
+// selected via LUMERA_VERSION and USE_LOCAL_IMAGE.
+func lumeraVersionFromEnv() (string, bool) {
+	version := DefaultLumeraVersion
+	if v := os.Getenv("LUMERA_VERSION"); v != "" {
+		version = v
+	}
+	return version, os.Getenv("USE_LOCAL_IMAGE") == "true"
+}
+
+// startLumera builds and starts a single-validator Lumera chain with the
+// given config. The chain is torn down when the test finishes.
+func startLumera(t *testing.T, ctx context.Context, config ibc.ChainConfig) *cosmos.CosmosChain {
+	t.Helper()
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	client, network := interchaintest.DockerSetup(t)
+
+	cf := interchaintest.NewBuiltinChainFactory(zaptest.NewLogger(t), []*interchaintest.ChainSpec{
+		{
+			ChainConfig:   config,
+			NumValidators: &[]int{1}[0],
+			NumFullNodes:  &[]int{0}[0],
+		},
+	})
+
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	lumera := chains[0].(*cosmos.CosmosChain)
+
+	ic := interchaintest.NewInterchain().AddChain(lumera)
+	require.NoError(t, ic.Build(ctx, eRep, interchaintest.InterchainBuildOptions{
+		TestName:  t.Name(),
+		Client:    client,
+		NetworkID: network,
+	}))
+	t.Cleanup(func() { _ = ic.Close() })
+
+	return lumera
+}
+
+// txEvent is a single ABCI event emitted by a committed tx.
+type txEvent struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"attributes"`
+}
+
+// txResult is the subset of a committed tx response inspected by the tests.
+type txResult struct {
+	TxHash string    `json:"txhash"`
+	Height string    `json:"height"`
+	Code   int       `json:"code"`
+	RawLog string    `json:"raw_log"`
+	Events []txEvent `json:"events"`
+}
+
+// eventAttribute returns the first value of key in events of the given type.
+func (r txResult) eventAttribute(eventType, key string) (string, bool) {
+	for _, ev := range r.Events {
+		if ev.Type != eventType {
+			continue
+		}
+		for _, attr := range ev.Attributes {
+			if attr.Key == key {
+				return attr.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// broadcastMsgs signs msgs (proto-JSON objects with an "@type" field) with
+// keyName and broadcasts them as a single tx. It returns the committed result
+// without asserting success, so callers can test rejections too.
+//
+// Message fields use lowerCamelCase JSON names: the decoder accepts those
+// whether the .proto field is spelled snake_case or camelCase.
+//
+// Going through "tx sign" + "tx broadcast" avoids depending on the exact
+// autocli command names of each Lumera module.
+func broadcastMsgs(
+	t *testing.T, ctx context.Context,
+	chain *cosmos.CosmosChain,
+	keyName string, msgs ...map[string]interface{},
+) txResult {
+	t.Helper()
+	cfg := chain.Config()
+
+	unsigned := map[string]interface{}{
+		"body": map[string]interface{}{
+			"messages":                       msgs,
+			"memo":                           "",
+			"timeout_height":                 "0",
+			"extension_options":              []interface{}{},
+			"non_critical_extension_options": []interface{}{},
+		},
+		"auth_info": map[string]interface{}{
+			"signer_infos": []interface{}{},
+			"fee": map[string]interface{}{
+				"amount":    []map[string]string{{"denom": cfg.Denom, "amount": fmt.Sprint(chain.GetGasFeesInNativeDenom(defaultTxGas))}},
+				"gas_limit": fmt.Sprint(defaultTxGas),
+				"payer":     "",
+				"granter":   "",
+			},
+		},
+		"signatures": []interface{}{},
+	}
+	unsignedJSON, err := json.Marshal(unsigned)
+	require.NoError(t, err)
+
+	suffix := time.Now().UnixNano()
+	unsignedFile := fmt.Sprintf("unsigned-%d.json", suffix)
+	signedFile := fmt.Sprintf("signed-%d.json", suffix)
+	require.NoError(t, chain.GetNode().WriteFile(ctx, unsignedJSON, unsignedFile))
+
+	signCmd := []string{
+		cfg.Bin, "tx", "sign", chain.HomeDir() + "/" + unsignedFile,
+		"--from", keyName,
+		"--chain-id", cfg.ChainID,
+		"--node", chain.GetRPCAddress(),
+		"--home", chain.HomeDir(),
+		"--keyring-backend", "test",
+		"--output-document", chain.HomeDir() + "/" + signedFile,
+	}
+	_, stderr, err := chain.Exec(ctx, signCmd, nil)
+	require.NoError(t, err, "tx sign failed: %s", string(stderr))
+
+	broadcastCmd := []string{
+		cfg.Bin, "tx", "broadcast", chain.HomeDir() + "/" + signedFile,
+		"--node", chain.GetRPCAddress(),
+		"--output", "json",
+	}
+	stdout, _, err := chain.Exec(ctx, broadcastCmd, nil)
+	require.NoError(t, err)
+
+	var resp txResult
+	require.NoError(t, json.Unmarshal(stdout, &resp), "failed to parse broadcast response: %s", string(stdout))
+	if resp.Code != 0 {
+		// Rejected in CheckTx — there is no committed result to wait for.
+		return resp
+	}
+
+	return waitForTx(t, ctx, chain, resp.TxHash)
+}
+
+// waitForTx polls until txHash is committed and returns its result.
+func waitForTx(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, txHash string) txResult {
+	t.Helper()
+	var res txResult
+	require.Eventually(t, func() bool {
+		r, err := queryTx(ctx, chain, txHash)
+		if err != nil {
+			return false
+		}
+		res = r
+		return true
+	}, time.Minute, 2*time.Second, "tx %s was not committed in time", txHash)
+	return res
+}
+
+// queryTx returns the committed result of txHash.
+func queryTx(ctx context.Context, chain *cosmos.CosmosChain, txHash string) (txResult, error) {
+	cmd := []string{
+		chain.Config().Bin, "q", "tx", txHash,
+		"--node", chain.GetRPCAddress(),
+		"--output", "json",
+	}
+	stdout, _, err := chain.Exec(ctx, cmd, nil)
+	if err != nil {
+		return txResult{}, err
+	}
+	var res txResult
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return txResult{}, fmt.Errorf("unmarshal tx query response: %w", err)
+	}
+	return res, nil
+}
+
+// requireTxSuccess fails the test if the tx was rejected.
+func requireTxSuccess(t *testing.T, res txResult) {
+	t.Helper()
+	require.Equal(t, 0, res.Code, "tx %s failed: %s", res.TxHash, res.RawLog)
+}
+
+// queryJSON runs "<bin> q <args...>" against the chain and unmarshals the
+// JSON response into out.
+func queryJSON(ctx context.Context, chain *cosmos.CosmosChain, out interface{}, args ...string) error {
+	cmd := append([]string{chain.Config().Bin, "q"}, args...)
+	cmd = append(cmd, "--node", chain.GetRPCAddress(), "--output", "json")
+	stdout, stderr, err := chain.Exec(ctx, cmd, nil)
+	if err != nil {
+		return fmt.Errorf("query %v: %w (%s)", args, err, string(stderr))
+	}
+	if err := json.Unmarshal(stdout, out); err != nil {
+		return fmt.Errorf("unmarshal query %v response: %w", args, err)
+	}
+	return nil
+}
@@ -0,0 +1,488 @@
+// ica_action_test.go — Action module economics for ICA-created actions: the fee
+// escrowed from the ICA on request, its split between the finalizing
+// supernode and the foundation (community pool) on completion, and its
+// return when the action expires unprocessed; plus the per-block cap on new
+// actions for batched ICA packets and direct txs.
+package interchaintest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	actionStatePending = "ACTION_STATE_PENDING"
+	actionStateDone    = "ACTION_STATE_DONE"
+	actionStateExpired = "ACTION_STATE_EXPIRED"
+)
+
+const (
+	// testActionExpiration replaces the 24h expiration_duration of the
+	// shipped genesis.
+	testActionExpiration = time.Minute
+	// testActionTTL is the expiration requested by the ICA. It must still be
+	// at least testActionExpiration ahead once the packet has been relayed
+	// and executed on the host.
+	testActionTTL = 3 * time.Minute
+
+	// testMaxActionsPerBlock lowers the shipped cap of 10 to keep batches
+	// small; each request carries full cascade metadata.
+	testMaxActionsPerBlock = 3
+)
+
+// maxActionsPerBlockErr matches the action module's error for a request over
+// the max_actions_per_block cap, so over-cap tests can't pass on an unrelated
+// failure such as insufficient funds or bad metadata.
+var maxActionsPerBlockErr = regexp.MustCompile(`(?i)max(imum)?[ _]actions[ _]per[ _]block`)
+
+// TestICAActionFeeDistribution creates a cascade action via ICA, finalizes it
+// from a registered supernode, and checks every ulume of the fee.
+func TestICAActionFeeDistribution(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping action fee e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithSupernodeMinimumStake(math.NewInt(supernodeTestMinStake)),
+	)
+
+	t.Logf("Testing action fee distribution on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, lumeraConfig)
+	registerICA(t, ctx, env)
+	fundICA(t, ctx, env.lumera, env.icaAddr)
+
+	lumera := env.lumera
+	denom := lumera.Config().Denom
+
+	// The supernode must be active before the action is requested so it is
+	// among the top supernodes for the action's block.
+	snAccount := registerTestSupernode(t, ctx, lumera)
+
+	params := queryActionParams(t, ctx, lumera)
+	t.Logf("Fee shares: super_node=%s foundation=%s", params.SuperNodeFeeShare, params.FoundationFeeShare)
+
+	// ── Request: the full price is escrowed from the ICA ──
+	icaBefore, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+
+	port, seq := sendICAPacket(t, ctx, env, buildCascadePacket(t, ctx, env))
+	ack := queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA MsgRequestAction failed: %s", ack.Error)
+
+	action := findActionByCreator(t, ctx, lumera, env.icaAddr)
+	require.Equal(t, actionStatePending, action.State)
+	price := action.Price.Amount
+	require.True(t, price.IsPositive(), "action price should be positive")
+	t.Logf("Action %s price: %s%s", action.ActionID, price, denom)
+
+	icaAfterRequest, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	require.Equal(t, icaBefore.Sub(price).String(), icaAfterRequest.String(),
+		"ICA should be debited exactly the action price")
+
+	// ── Finalize: the fee is split between supernode and foundation ──
+	snBefore, err := lumera.GetBalance(ctx, snAccount.FormattedAddress(), denom)
+	require.NoError(t, err)
+
+	res := broadcastMsgs(t, ctx, lumera, snAccount.KeyName(),
+		msgFinalizeAction(snAccount.FormattedAddress(), action.ActionID, finalizeMetadata(t, ctx, lumera, action.ActionID)))
+	requireTxSuccess(t, res)
+	require.Equal(t, actionStateDone, queryAction(t, ctx, lumera, action.ActionID).State)
+
+	// The action module rounds once: the foundation share is truncated and
+	// the supernode gets the remainder, so price×super_node_fee_share may be
+	// one unit short of supernodeFee.
+	foundationFee := price.ToLegacyDec().Mul(params.FoundationFeeShare).TruncateInt()
+	supernodeFee := price.Sub(foundationFee)
+	t.Logf("Expected split: supernode=%s foundation=%s", supernodeFee, foundationFee)
+
+	// The supernode paid the finalize tx fee itself.
+	txFee := math.NewInt(lumera.GetGasFeesInNativeDenom(defaultTxGas))
+	snAfter, err := lumera.GetBalance(ctx, snAccount.FormattedAddress(), denom)
+	require.NoError(t, err)
+	require.Equal(t, snBefore.Sub(txFee).Add(supernodeFee).String(), snAfter.String(),
+		"supernode should receive exactly its fee share")
+
+	// The community pool also accrues block rewards and the community tax,
+	// so its balance can't isolate the foundation share. Instead, take the
+	// transfers the finalize tx made from the action module's escrow: the
+	// foundation share is funded into the community pool, i.e. sent to the
+	// distribution module account.
+	actionModule := moduleAddress(t, lumera, actionModuleName)
+	require.Equal(t, foundationFee.String(),
+		transferredAmount(t, res, actionModule, moduleAddress(t, lumera, distrtypes.ModuleName), denom).String(),
+		"community pool should receive exactly the foundation share")
+	require.Equal(t, supernodeFee.String(),
+		transferredAmount(t, res, actionModule, snAccount.FormattedAddress(), denom).String(),
+		"escrow should pay the supernode exactly its fee share")
+
+	icaAfter, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	require.Equal(t, icaAfterRequest.String(), icaAfter.String(), "finalization must not touch the ICA balance")
+}
+
+// TestICAActionExpiration creates a cascade action via ICA with a short
+// expiration, leaves it unprocessed, and checks that it expires and that the
+// escrowed fee goes back to the ICA.
+func TestICAActionExpiration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping action expiration e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithSupernodeMinimumStake(math.NewInt(supernodeTestMinStake)),
+		WithActionExpiration(testActionExpiration),
+	)
+
+	t.Logf("Testing action expiration on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, lumeraConfig)
+	registerICA(t, ctx, env)
+	fundICA(t, ctx, env.lumera, env.icaAddr)
+
+	lumera := env.lumera
+	denom := lumera.Config().Denom
+
+	// min_super_nodes is 1: a request needs an active supernode even though
+	// it will never process the action.
+	snAccount := registerTestSupernode(t, ctx, lumera)
+
+	params := queryActionParams(t, ctx, lumera)
+	require.Equal(t, durationParam(testActionExpiration), params.ExpirationDuration)
+
+	icaBefore, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+
+	port, seq := sendICAPacket(t, ctx, env, buildCascadePacket(t, ctx, env, "--expiration", testActionTTL.String()))
+	ack := queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA MsgRequestAction failed: %s", ack.Error)
+
+	action := findActionByCreator(t, ctx, lumera, env.icaAddr)
+	require.Equal(t, actionStatePending, action.State)
+	price := action.Price.Amount
+
+	icaAfterRequest, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	require.Equal(t, icaBefore.Sub(price).String(), icaAfterRequest.String(),
+		"ICA should be debited exactly the action price")
+
+	expiresAt, err := strconv.ParseInt(action.ExpirationTime, 10, 64)
+	require.NoError(t, err, "invalid expiration time %q", action.ExpirationTime)
+	t.Logf("Action %s (price %s%s) expires at %s", action.ActionID, price, denom, time.Unix(expiresAt, 0).UTC())
+
+	// ── Let the action lapse ──
+	// Expiry happens in the action module's EndBlocker, so the refund is not
+	// part of any tx; remember where to look for it in block results.
+	fromHeight, err := lumera.Height(ctx)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return queryAction(t, ctx, lumera, action.ActionID).State == actionStateExpired
+	}, time.Until(time.Unix(expiresAt, 0))+2*time.Minute, 5*time.Second,
+		"action %s should expire unprocessed", action.ActionID)
+
+	toHeight, err := lumera.Height(ctx)
+	require.NoError(t, err)
+
+	// The escrowed price is refunded to the creator on expiry: the block
+	// that expired the action moved exactly the price from the action
+	// module back to the ICA.
+	refunded := blockTransferredAmount(t, ctx, lumera, fromHeight, toHeight,
+		moduleAddress(t, lumera, actionModuleName), env.icaAddr, denom)
+	require.Equal(t, price.String(), refunded.String(), "expiry should refund exactly the action price")
+	icaAfter, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	require.Equal(t, icaBefore.String(), icaAfter.String(), "expired action fee should be refunded to the ICA")
+
+	// An expired action can no longer be finalized.
+	res := broadcastMsgs(t, ctx, lumera, snAccount.KeyName(),
+		msgFinalizeAction(snAccount.FormattedAddress(), action.ActionID, finalizeMetadata(t, ctx, lumera, action.ActionID)))
+	require.NotEqual(t, 0, res.Code, "finalizing an expired action must fail")
+	require.Equal(t, actionStateExpired, queryAction(t, ctx, lumera, action.ActionID).State)
+}
+
+// TestICAActionMaxPerBlock submits more than max_actions_per_block requests in
+// one block, once as a batched ICA packet and once as a direct tx, and checks
+// that each is rejected as a whole.
+func TestICAActionMaxPerBlock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping max actions per block e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithSupernodeMinimumStake(math.NewInt(supernodeTestMinStake)),
+		WithActionMaxPerBlock(testMaxActionsPerBlock),
+	)
+
+	t.Logf("Testing max_actions_per_block on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, lumeraConfig)
+	registerICA(t, ctx, env)
+	fundICA(t, ctx, env.lumera, env.icaAddr)
+
+	lumera := env.lumera
+	registerTestSupernode(t, ctx, lumera)
+
+	params := queryActionParams(t, ctx, lumera)
+	require.Equal(t, fmt.Sprint(testMaxActionsPerBlock), params.MaxActionsPerBlock)
+
+	// All messages of an ICA packet execute in one host tx, hence one block.
+	t.Run("ICABatchOverCapRejected", func(t *testing.T) {
+		denom := lumera.Config().Denom
+		before := len(actionsByCreator(t, ctx, lumera, env.icaAddr))
+		balBefore, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+
+		port, seq := sendICAPacket(t, ctx, env, buildCascadeBatchPacket(t, ctx, env, testMaxActionsPerBlock+1))
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.False(t, ack.Success(), "packet with %d requests must be rejected", testMaxActionsPerBlock+1)
+		// The ack itself only carries the deterministic ABCI code; the
+		// reason is in the host's ics27_packet event.
+		require.Contains(t, ack.Ack, "ABCI code")
+		require.Regexp(t, maxActionsPerBlockErr, ack.Error, "packet should fail on the action cap")
+
+		// Atomic: none of the first max_actions_per_block requests survive
+		// and no fee was escrowed.
+		require.Len(t, actionsByCreator(t, ctx, lumera, env.icaAddr), before, "no action from the rejected packet may be stored")
+		balAfter, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+		require.Equal(t, balBefore.String(), balAfter.String(), "rejected packet must not charge the ICA")
+	})
+
+	t.Run("ICABatchAtCapAccepted", func(t *testing.T) {
+		before := len(actionsByCreator(t, ctx, lumera, env.icaAddr))
+
+		port, seq := sendICAPacket(t, ctx, env, buildCascadeBatchPacket(t, ctx, env, testMaxActionsPerBlock))
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.True(t, ack.Success(), "packet with %d requests should fit the cap: %s", testMaxActionsPerBlock, ack.Error)
+		require.Len(t, actionsByCreator(t, ctx, lumera, env.icaAddr), before+testMaxActionsPerBlock)
+	})
+
+	// The same key that owns the ICA on Osmosis also controls a plain
+	// Lumera account, which can sign the cascade metadata for direct txs.
+	requester, err := interchaintest.GetAndFundTestUserWithMnemonic(ctx, "requester", env.mnemonic, math.NewInt(10_000_000_000), lumera)
+	require.NoError(t, err)
+
+	t.Run("DirectTxOverCapRejected", func(t *testing.T) {
+		msgs := cascadeRequestMsgs(t, ctx, lumera, env.mnemonic, testMaxActionsPerBlock+1)
+		res := broadcastMsgs(t, ctx, lumera, requester.KeyName(), msgs...)
+		require.NotEqual(t, 0, res.Code, "tx with %d requests must be rejected", len(msgs))
+		t.Logf("Over-cap tx rejected: %s", res.RawLog)
+		// The first max_actions_per_block messages pass; the next one fails
+		// and reverts the whole tx.
+		require.Contains(t, res.RawLog, fmt.Sprintf("message index: %d", testMaxActionsPerBlock))
+		require.Regexp(t, maxActionsPerBlockErr, res.RawLog, "tx should fail on the action cap")
+		require.Empty(t, actionsByCreator(t, ctx, lumera, requester.FormattedAddress()))
+	})
+
+	t.Run("DirectTxAtCapAccepted", func(t *testing.T) {
+		msgs := cascadeRequestMsgs(t, ctx, lumera, env.mnemonic, testMaxActionsPerBlock)
+		res := broadcastMsgs(t, ctx, lumera, requester.KeyName(), msgs...)
+		requireTxSuccess(t, res)
+		require.Len(t, actionsByCreator(t, ctx, lumera, requester.FormattedAddress()), testMaxActionsPerBlock)
+	})
+}
+
+// coinAmount decodes an action price rendered either as a coin object
+// ({"denom":"ulume","amount":"10010"}) or as a coin string ("10010ulume").
+type coinAmount struct {
+	Denom  string
+	Amount math.Int
+}
+
+func (c *coinAmount) UnmarshalJSON(bz []byte) error {
+	var obj struct {
+		Denom  string `json:"denom"`
+		Amount string `json:"amount"`
+	}
+	s := ""
+	if err := json.Unmarshal(bz, &obj); err == nil {
+		c.Denom, s = obj.Denom, obj.Amount
+	} else {
+		if err := json.Unmarshal(bz, &s); err != nil {
+			return fmt.Errorf("price is neither a coin nor a string: %s", string(bz))
+		}
+		i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		if i >= 0 {
+			s, c.Denom = s[:i], s[i:]
+		}
+	}
+	amount, ok := math.NewIntFromString(s)
+	if !ok {
+		return fmt.Errorf("invalid price amount %q", s)
+	}
+	c.Amount = amount
+	return nil
+}
+
+// actionInfo is the subset of an action query response used by tests.
+type actionInfo struct {
+	Creator        string     `json:"creator"`
+	ActionID       string     `json:"actionID"`
+	ActionType     string     `json:"actionType"`
+	State          string     `json:"state"`
+	Price          coinAmount `json:"price"`
+	ExpirationTime string     `json:"expirationTime"`
+	SuperNodes     []string   `json:"superNodes"`
+}
+
+func listActions(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) []actionInfo {
+	t.Helper()
+	var resp struct {
+		Actions []actionInfo `json:"actions"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "action", "list-actions"))
+	return resp.Actions
+}
+
+// findActionByCreator returns the most recent action created by creator.
+func findActionByCreator(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, creator string) actionInfo {
+	t.Helper()
+	actions := actionsByCreator(t, ctx, lumera, creator)
+	require.NotEmpty(t, actions, "no action created by %s", creator)
+	return actions[len(actions)-1]
+}
+
+// actionsByCreator returns all actions created by creator, oldest first.
+func actionsByCreator(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, creator string) []actionInfo {
+	t.Helper()
+	var out []actionInfo
+	for _, a := range listActions(t, ctx, lumera) {
+		if a.Creator == creator {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func queryAction(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, actionID string) actionInfo {
+	t.Helper()
+	var resp struct {
+		Action actionInfo `json:"action"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "action", "get-action", actionID))
+	return resp.Action
+}
+
+// actionParams is the subset of action module params used by tests.
+type actionParams struct {
+	SuperNodeFeeShare  math.LegacyDec
+	FoundationFeeShare math.LegacyDec
+	ExpirationDuration string
+	MaxActionsPerBlock string
+}
+
+func queryActionParams(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) actionParams {
+	t.Helper()
+	var resp struct {
+		Params struct {
+			SuperNodeFeeShare  string `json:"super_node_fee_share"`
+			FoundationFeeShare string `json:"foundation_fee_share"`
+			ExpirationDuration string `json:"expiration_duration"`
+			MaxActionsPerBlock string `json:"max_actions_per_block"`
+		} `json:"params"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "action", "params"))
+
+	snShare, err := math.LegacyNewDecFromStr(resp.Params.SuperNodeFeeShare)
+	require.NoError(t, err)
+	foundationShare, err := math.LegacyNewDecFromStr(resp.Params.FoundationFeeShare)
+	require.NoError(t, err)
+	return actionParams{
+		SuperNodeFeeShare:  snShare,
+		FoundationFeeShare: foundationShare,
+		ExpirationDuration: resp.Params.ExpirationDuration,
+		MaxActionsPerBlock: resp.Params.MaxActionsPerBlock,
+	}
+}
+
+// actionModuleName is the action module's name, which derives its
+// account address.
+const actionModuleName = "action"
+
+// moduleAddress returns the bech32 account address of module on chain.
+func moduleAddress(t *testing.T, chain *cosmos.CosmosChain, module string) string {
+	t.Helper()
+	addr, err := bech32.ConvertAndEncode(chain.Config().Bech32Prefix, authtypes.NewModuleAddress(module))
+	require.NoError(t, err)
+	return addr
+}
+
+// blockTransferredAmount is like transferredAmount for the block-level events
+// (begin and end blockers) of heights from through to.
+func blockTransferredAmount(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, from, to int64, sender, recipient, denom string) math.Int {
+	t.Helper()
+	total := math.ZeroInt()
+	for h := from; h <= to; h++ {
+		results, err := chain.GetNode().Client.BlockResults(ctx, &h)
+		require.NoError(t, err, "block results at height %d", h)
+		bz, err := json.Marshal(results.FinalizeBlockEvents)
+		require.NoError(t, err)
+		var block txResult
+		require.NoError(t, json.Unmarshal(bz, &block.Events))
+		total = total.Add(transferredAmount(t, block, sender, recipient, denom))
+	}
+	return total
+}
+
+// transferredAmount sums the denom amounts of res's bank transfer events from
+// sender to recipient.
+func transferredAmount(t *testing.T, res txResult, sender, recipient, denom string) math.Int {
+	t.Helper()
+	total := math.ZeroInt()
+	for _, ev := range res.Events {
+		if ev.Type != "transfer" {
+			continue
+		}
+		attrs := make(map[string]string, len(ev.Attributes))
+		for _, attr := range ev.Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		if attrs["sender"] != sender || attrs["recipient"] != recipient {
+			continue
+		}
+		coins, err := sdk.ParseCoinsNormalized(attrs["amount"])
+		require.NoError(t, err, "invalid transfer amount %q", attrs["amount"])
+		total = total.Add(coins.AmountOf(denom))
+	}
+	return total
+}
+
+// finalizeMetadata runs the buildpacket tool in finalize-metadata mode and
+// returns the MsgFinalizeAction metadata for actionID.
+func finalizeMetadata(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, actionID string) string {
+	t.Helper()
+	return string(runBuildpacket(t, ctx, lumera, "--mode", "finalize-metadata", "--action-id", actionID))
+}
+
+func msgFinalizeAction(creator, actionID, metadata string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":      "/lumera.action.v1.MsgFinalizeAction",
+		"creator":    creator,
+		"actionId":   actionID,
+		"actionType": "CASCADE",
+		"metadata":   metadata,
+	}
+}
@@ -0,0 +1,75 @@
+// ica_claims_test.go — Claiming a Pastel-era balance into a Lumera interchain
+// account from Osmosis. MsgClaim is signed by its new_address, so with the
+// ICA as destination the host can execute it on the ICA's behalf; the Pastel
+// key only signs the claim payload, off-chain.
+package interchaintest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestICAClaim claims a synthetic Pastel entry into the ICA via a packet
+// built by buildpacket.
+func TestICAClaim(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA claim e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+
+	entries := GenerateClaims(5_000_000_000)
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithClaimsCSV(ClaimsCSV(entries)),
+		WithClaimEndTime(24*time.Hour),
+	)
+
+	t.Logf("Testing claims via ICA on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, lumeraConfig)
+	registerICA(t, ctx, env)
+
+	lumera := env.lumera
+	denom := lumera.Config().Denom
+	entry := entries[0]
+
+	before, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	remainingBefore := claimableRemaining(t, ctx, lumera)
+
+	port, seq := sendICAPacket(t, ctx, env, buildClaimPacket(t, ctx, env, entry))
+	ack := queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA MsgClaim failed: %s", ack.Error)
+
+	after, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	require.Equal(t, before.Add(entry.Amount).String(), after.String(), "claimed balance should be credited to the ICA")
+
+	rec := queryClaimRecord(t, ctx, lumera, entry.Key.Address())
+	require.True(t, rec.Claimed)
+	require.Equal(t, env.icaAddr, rec.DestAddress)
+	require.Equal(t, remainingBefore.Sub(entry.Amount).String(), claimableRemaining(t, ctx, lumera).String())
+}
+
+// buildClaimPacket returns ICA packet JSON with a MsgClaim moving entry's
+// balance to env.icaAddr, signed with entry's Pastel key.
+func buildClaimPacket(t *testing.T, ctx context.Context, env *icaTestEnv, entry ClaimEntry) []byte {
+	t.Helper()
+	sig, err := entry.Key.SignClaim(env.icaAddr)
+	require.NoError(t, err)
+
+	packetJSON := runBuildpacket(t, ctx, env.lumera,
+		"--mode", "claim",
+		"--ica-address", env.icaAddr,
+		"--old-address", entry.Key.Address(),
+		"--pub-key", entry.Key.PubKeyHex(),
+		"--signature", sig,
+	)
+	require.NotEmpty(t, packetJSON, "buildpacket produced empty output")
+	t.Logf("ICA packet data: %s", string(packetJSON))
+	return packetJSON
+}
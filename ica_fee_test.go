@@ -0,0 +1,340 @@
+// ica_fee_test.go — ICS-29 (fee middleware) incentivized ICA packets. The
+// controller opens its ICA channel with fee-enabled version metadata, the
+// relayer registers a payee on both ends, and the owner escrows recv, ack and
+// timeout fees for an ICA packet in the same tx that sends it. Once the packet
+// completes, the payee must hold the recv and ack fees and the timeout fee
+// must be refunded to the owner.
+//
+// Osmosis has no fee middleware, so the controller is ibc-go's simd unless
+// ICA_CONTROLLER names another chain marked FeeMiddleware in the registry.
+//
+// Lumera v1.10.0 and later are built on ibc-go v10, which removed ICS-29:
+// the incentivized test only runs on older releases (lumeraRelease
+// feeMiddleware), and TestICAFeeVersionRejected checks that newer ones refuse
+// a fee-enabled ICA channel.
+//
+// Test topology:
+//
+//	simd (controller) ──IBC (ics29-1 wrapping ics27-1)──▶ Lumera (host)
+//	   │                                                     │
+//	   │  1. pay packet fee + send-tx ─────────────────────▶ │  2. execute MsgSend,
+//	   │  4. distribute recv/ack fees, refund timeout fee ◀─ │  3. ack names the counterparty payee
+package interchaintest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	feetypes "github.com/cosmos/ibc-go/v8/modules/apps/29-fee/types"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/strangelove-ventures/interchaintest/v8/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// Distinct recv, ack and timeout fees (in the controller's denom), so a
+	// fee paid to the wrong party shows up in the balances.
+	icaRecvFee    = 3_000_000
+	icaAckFee     = 2_000_000
+	icaTimeoutFee = 1_000_000
+	// icaFeeSendAmount is sent by the ICA on Lumera in the incentivized packet.
+	icaFeeSendAmount = 1_000_000
+	// icaFeePacketTimeout is the ICA packet's relative timeout.
+	icaFeePacketTimeout = 10 * time.Minute
+)
+
+// TestICAFeeIncentivizedPacket relays a fee-incentivized ICA packet from a
+// controller with the ICS-29 middleware to Lumera.
+func TestICAFeeIncentivizedPacket(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA fee e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	if !lumeraReleaseFor(version).feeMiddleware {
+		t.Skipf("Lumera %s is built on ibc-go v10, which removed the ICS-29 fee middleware (feeibc)", version)
+	}
+	controllerChain, controllerVersion, err := feeControllerFromEnv()
+	require.NoError(t, err)
+
+	t.Logf("Testing incentivized ICA packets with Lumera %s (local image: %v), %s %s", version, useLocal, controllerChain.Name, controllerVersion)
+
+	env := newICATestEnvWithController(t, ctx, controllerChain, controllerVersion, GetLumeraChainConfig(version, useLocal))
+	controller, lumera := env.controller, env.lumera
+	denom := controller.Config().Denom
+	owner := env.user.FormattedAddress()
+
+	// ── Open a fee-enabled ICA channel ──
+	registerICA(t, ctx, env, "--version", icaFeeVersion(env.connectionID, hostConnectionID(t, ctx, env)))
+	fundICA(t, ctx, lumera, env.icaAddr)
+
+	controllerPort, err := icatypes.NewControllerPortID(owner)
+	require.NoError(t, err)
+	controllerCh := channelByPort(t, ctx, env, controller, controllerPort)
+	hostPort, hostChannel := controllerCh.Counterparty.PortID, controllerCh.Counterparty.ChannelID
+
+	t.Run("FeeEnabledChannel", func(t *testing.T) {
+		metadata, err := feetypes.MetadataFromVersion(controllerCh.Version)
+		require.NoError(t, err, "ICA channel version is not fee metadata: %s", controllerCh.Version)
+		require.Equal(t, feetypes.Version, metadata.FeeVersion)
+		_, err = icatypes.MetadataFromVersion(metadata.AppVersion)
+		require.NoError(t, err, "fee metadata should wrap the ICS-27 version: %s", metadata.AppVersion)
+
+		require.True(t, feeEnabled(t, ctx, controller, controllerCh.PortID, controllerCh.ChannelID), "controller channel should be fee enabled")
+		require.True(t, feeEnabled(t, ctx, lumera, hostPort, hostChannel), "Lumera channel should be fee enabled")
+	})
+
+	// ── Register the relayer's payees ──
+	// Both fees go to one fresh controller account: the recv fee through the
+	// counterparty payee registered on Lumera (where the relayer submits
+	// MsgRecvPacket), the ack fee through the payee registered on the
+	// controller (where it submits MsgAcknowledgement). The relayer is idle
+	// between handshakes, so signing with its keys here doesn't race it.
+	payee, err := controller.BuildWallet(ctx, "fee-payee", "")
+	require.NoError(t, err)
+	payeeAddr := payee.FormattedAddress()
+
+	lumeraRelayer := importRelayerWallet(t, ctx, env, lumera)
+	requireTxSuccess(t, broadcastMsgs(t, ctx, lumera, lumeraRelayer.KeyName(),
+		msgRegisterCounterpartyPayee(hostPort, hostChannel, lumeraRelayer.FormattedAddress(), payeeAddr)))
+	controllerRelayer := importRelayerWallet(t, ctx, env, controller)
+	requireTxSuccess(t, broadcastMsgs(t, ctx, controller, controllerRelayer.KeyName(),
+		msgRegisterPayee(controllerCh.PortID, controllerCh.ChannelID, controllerRelayer.FormattedAddress(), payeeAddr)))
+
+	// ── Escrow fees and send the ICA packet in one tx ──
+	// MsgPayPacketFee escrows fees for the channel's next sequence, so it must
+	// precede the send in the same tx for the relayer to see them in time.
+	recipient, err := lumera.BuildWallet(ctx, "fee-ica-recipient", "")
+	require.NoError(t, err)
+	packet := generateICAPacket(t, ctx, lumera,
+		msgSend(env.icaAddr, recipient.FormattedAddress(), lumera.Config().Denom, math.NewInt(icaFeeSendAmount)))
+
+	ownerBefore, err := controller.GetBalance(ctx, owner, denom)
+	require.NoError(t, err)
+
+	res := broadcastMsgs(t, ctx, controller, env.user.KeyName(),
+		msgPayPacketFee(controllerCh.PortID, controllerCh.ChannelID, owner, denom),
+		msgSendTx(t, owner, env.connectionID, packet),
+	)
+	requireTxSuccess(t, res)
+	txFee := controller.GetGasFeesInNativeDenom(defaultTxGas)
+	seq, ok := res.eventAttribute("send_packet", "packet_sequence")
+	require.True(t, ok, "send-tx should emit send_packet")
+	t.Logf("Incentivized ICA packet %s/%s sequence %s", controllerCh.PortID, controllerCh.ChannelID, seq)
+
+	t.Run("FeesEscrowed", func(t *testing.T) {
+		for key, amount := range map[string]int64{"recv_fee": icaRecvFee, "ack_fee": icaAckFee, "timeout_fee": icaTimeoutFee} {
+			fee, ok := res.eventAttribute(feetypes.EventTypeIncentivizedPacket, key)
+			require.True(t, ok, "pay packet fee should emit %s", key)
+			require.Equal(t, fmt.Sprintf("%d%s", amount, denom), fee, key)
+		}
+	})
+
+	flushPath(t, ctx, env, controller, controllerCh.ChannelID, hostChannel)
+
+	t.Run("ForwardRelayerIsPayee", func(t *testing.T) {
+		ackRes := queryAckTx(t, ctx, lumera, controllerCh.PortID, controllerCh.ChannelID, seq)
+		rawAck, _ := ackRes.eventAttribute("write_acknowledgement", "packet_ack")
+		var ack struct {
+			AppAcknowledgement    []byte `json:"app_acknowledgement"`
+			ForwardRelayerAddress string `json:"forward_relayer_address"`
+			UnderlyingAppSuccess  bool   `json:"underlying_app_success"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(rawAck), &ack), "ack is not an incentivized acknowledgement: %s", rawAck)
+		icaErr, _ := ackRes.eventAttribute("ics27_packet", "error")
+		require.True(t, ack.UnderlyingAppSuccess, "ICA MsgSend failed: %s", icaErr)
+		require.Equal(t, payeeAddr, ack.ForwardRelayerAddress, "Lumera should name the counterparty payee as forward relayer")
+
+		waitForBalance(t, ctx, lumera, recipient.FormattedAddress(), lumera.Config().Denom, math.NewInt(icaFeeSendAmount))
+	})
+
+	t.Run("FeesDistributed", func(t *testing.T) {
+		waitForBalance(t, ctx, controller, payeeAddr, denom, math.NewInt(icaRecvFee+icaAckFee))
+
+		// The timeout fee comes back to the owner once the packet is acked.
+		ownerAfter, err := controller.GetBalance(ctx, owner, denom)
+		require.NoError(t, err)
+		require.Equal(t, ownerBefore.SubRaw(txFee+icaRecvFee+icaAckFee).String(), ownerAfter.String())
+
+		var packets struct {
+			IncentivizedPackets []json.RawMessage `json:"incentivized_packets"`
+		}
+		require.NoError(t, queryJSON(ctx, controller, &packets, "ibc-fee", "packets-for-channel", controllerCh.PortID, controllerCh.ChannelID))
+		require.Empty(t, packets.IncentivizedPackets, "fees should be released from escrow")
+	})
+}
+
+// TestICAFeeVersionRejected asks a Lumera release without the fee middleware
+// for a fee-enabled ICA channel. The host can't parse the ICS-29 metadata, so
+// the handshake must either fail (no ICA, channel not open) or end on a plain
+// ICS-27 version; it must never open a fee-enabled channel.
+func TestICAFeeVersionRejected(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA fee e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	if lumeraReleaseFor(version).feeMiddleware {
+		t.Skipf("Lumera %s still has the ICS-29 fee middleware; see TestICAFeeIncentivizedPacket", version)
+	}
+	controllerChain, controllerVersion, err := feeControllerFromEnv()
+	require.NoError(t, err)
+
+	t.Logf("Testing fee version rejection with Lumera %s (local image: %v), %s %s", version, useLocal, controllerChain.Name, controllerVersion)
+
+	env := newICATestEnvWithController(t, ctx, controllerChain, controllerVersion, GetLumeraChainConfig(version, useLocal))
+	controller, lumera := env.controller, env.lumera
+	owner := env.user.FormattedAddress()
+
+	submitRegisterICA(t, ctx, env, "--version", icaFeeVersion(env.connectionID, hostConnectionID(t, ctx, env)))
+
+	// Give the relayer time to attempt the handshake.
+	require.NoError(t, testutil.WaitForBlocks(ctx, 20, controller, lumera))
+
+	controllerPort, err := icatypes.NewControllerPortID(owner)
+	require.NoError(t, err)
+	controllerCh := channelByPort(t, ctx, env, controller, controllerPort)
+	t.Logf("ICA channel %s/%s: state %s, version %s", controllerCh.PortID, controllerCh.ChannelID, controllerCh.State, controllerCh.Version)
+
+	addr, err := tryQueryICAAddress(ctx, controller, env.connectionID, owner)
+	if err != nil || addr == "" {
+		// Rejected: the controller's end never gets past INIT.
+		require.NotEqual(t, "STATE_OPEN", controllerCh.State, "channel opened without an ICA address")
+		return
+	}
+
+	// Negotiated down: the channel opened on plain ICS-27 metadata.
+	require.Equal(t, "STATE_OPEN", controllerCh.State)
+	_, err = feetypes.MetadataFromVersion(controllerCh.Version)
+	require.Error(t, err, "Lumera %s opened a fee-enabled ICA channel: %s", version, controllerCh.Version)
+	_, err = icatypes.MetadataFromVersion(controllerCh.Version)
+	require.NoError(t, err, "channel version is not ICS-27 metadata: %s", controllerCh.Version)
+	require.False(t, feeEnabled(t, ctx, controller, controllerCh.PortID, controllerCh.ChannelID), "controller channel should not be fee enabled")
+}
+
+// TestICAFeeVersion checks the fee-enabled ICA channel version offline.
+func TestICAFeeVersion(t *testing.T) {
+	version := icaFeeVersion("connection-0", "connection-3")
+
+	metadata, err := feetypes.MetadataFromVersion(version)
+	require.NoError(t, err)
+	require.Equal(t, feetypes.Version, metadata.FeeVersion)
+
+	app, err := icatypes.MetadataFromVersion(metadata.AppVersion)
+	require.NoError(t, err)
+	require.Equal(t, icatypes.Version, app.Version)
+	require.Equal(t, "connection-0", app.ControllerConnectionId)
+	require.Equal(t, "connection-3", app.HostConnectionId)
+	require.Equal(t, icatypes.EncodingProtobuf, app.Encoding)
+	require.Equal(t, icatypes.TxTypeSDKMultiMsg, app.TxType)
+}
+
+// icaFeeVersion returns the channel version for registering an ICA over a
+// fee-enabled channel: ICS-29 metadata wrapping the default ICS-27 metadata.
+func icaFeeVersion(controllerConnectionID, hostConnectionID string) string {
+	metadata := feetypes.Metadata{
+		FeeVersion: feetypes.Version,
+		AppVersion: icatypes.NewDefaultMetadataString(controllerConnectionID, hostConnectionID),
+	}
+	return string(feetypes.ModuleCdc.MustMarshalJSON(&metadata))
+}
+
+// hostConnectionID returns Lumera's end of env.connectionID.
+func hostConnectionID(t *testing.T, ctx context.Context, env *icaTestEnv) string {
+	t.Helper()
+	connections, err := env.relayer.GetConnections(ctx, env.eRep, env.controller.Config().ChainID)
+	require.NoError(t, err)
+	for _, c := range connections {
+		if c.ID == env.connectionID {
+			return c.Counterparty.ConnectionId
+		}
+	}
+	require.FailNow(t, "connection not found", "%s has no connection %s", env.controller.Config().Name, env.connectionID)
+	return ""
+}
+
+// feeEnabled reports whether chain's port/channel runs the fee middleware.
+func feeEnabled(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, port, channel string) bool {
+	t.Helper()
+	var resp struct {
+		FeeEnabled bool `json:"fee_enabled"`
+	}
+	require.NoError(t, queryJSON(ctx, chain, &resp, "ibc-fee", "channel", port, channel))
+	return resp.FeeEnabled
+}
+
+// importRelayerWallet adds the relayer's key on chain to the chain's keyring,
+// so tests can sign as the relayer.
+func importRelayerWallet(t *testing.T, ctx context.Context, env *icaTestEnv, chain *cosmos.CosmosChain) ibc.Wallet {
+	t.Helper()
+	relayerWallet, ok := env.relayer.GetWallet(chain.Config().ChainID)
+	require.True(t, ok, "relayer has no wallet on %s", chain.Config().Name)
+	wallet, err := chain.BuildWallet(ctx, "fee-relayer", relayerWallet.Mnemonic())
+	require.NoError(t, err)
+	require.Equal(t, relayerWallet.FormattedAddress(), wallet.FormattedAddress())
+	return wallet
+}
+
+func msgRegisterPayee(port, channel, relayer, payee string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":     "/ibc.applications.fee.v1.MsgRegisterPayee",
+		"portId":    port,
+		"channelId": channel,
+		"relayer":   relayer,
+		"payee":     payee,
+	}
+}
+
+func msgRegisterCounterpartyPayee(port, channel, relayer, counterpartyPayee string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":             "/ibc.applications.fee.v1.MsgRegisterCounterpartyPayee",
+		"portId":            port,
+		"channelId":         channel,
+		"relayer":           relayer,
+		"counterpartyPayee": counterpartyPayee,
+	}
+}
+
+// msgPayPacketFee escrows the icaRecvFee, icaAckFee and icaTimeoutFee from
+// signer for the next packet sent on port/channel, open to any relayer.
+func msgPayPacketFee(port, channel, signer, denom string) map[string]interface{} {
+	coins := func(amount int64) []map[string]string {
+		return []map[string]string{{"denom": denom, "amount": math.NewInt(amount).String()}}
+	}
+	return map[string]interface{}{
+		"@type": "/ibc.applications.fee.v1.MsgPayPacketFee",
+		"fee": map[string]interface{}{
+			"recvFee":    coins(icaRecvFee),
+			"ackFee":     coins(icaAckFee),
+			"timeoutFee": coins(icaTimeoutFee),
+		},
+		"sourcePortId":    port,
+		"sourceChannelId": channel,
+		"signer":          signer,
+		"relayers":        []string{},
+	}
+}
+
+// msgSendTx sends packetJSON (as built by generateICAPacket) from owner's ICA
+// over connectionID.
+func msgSendTx(t *testing.T, owner, connectionID string, packetJSON []byte) map[string]interface{} {
+	t.Helper()
+	var packetData map[string]interface{}
+	require.NoError(t, json.Unmarshal(packetJSON, &packetData), "invalid ICA packet JSON: %s", string(packetJSON))
+	return map[string]interface{}{
+		"@type":           "/ibc.applications.interchain_accounts.controller.v1.MsgSendTx",
+		"owner":           owner,
+		"connectionId":    connectionID,
+		"packetData":      packetData,
+		"relativeTimeout": fmt.Sprint(icaFeePacketTimeout.Nanoseconds()),
+	}
+}
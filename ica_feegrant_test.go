@@ -0,0 +1,134 @@
+// ica_feegrant_test.go — Fee grants and interchain accounts. A Lumera sponsor
+// grants a feegrant allowance to an unfunded ICA, which then executes
+// packets. Host execution charges no gas fee (see fundICA), so the allowance
+// is never consumed: the ICA needs no LUME for gas at all. Module fees are
+// another matter — the cascade action fee is paid by the creator, and a fee
+// grant can't cover it.
+package interchaintest_test
+
+import (
+	"context"
+	"testing"
+
+	"cosmossdk.io/math"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+// icaFeeAllowance is the sponsor's spend limit for the ICA, in ulume.
+const icaFeeAllowance = 5_000_000
+
+// TestICAFeeGrant sends ICA packets from an unfunded ICA holding a feegrant
+// allowance, and checks that neither the ICA nor the allowance is charged.
+func TestICAFeeGrant(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA fee grant e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+
+	t.Logf("Testing fee grants for an ICA on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, GetLumeraChainConfig(version, useLocal))
+	registerICA(t, ctx, env)
+
+	lumera := env.lumera
+	denom := lumera.Config().Denom
+
+	// ── Sponsor grants an allowance to the (unfunded) ICA ──
+	sponsor := interchaintest.GetAndFundTestUsers(t, ctx, "sponsor", math.NewInt(10_000_000_000), lumera)[0]
+	requireTxSuccess(t, broadcastMsgs(t, ctx, lumera, sponsor.KeyName(),
+		msgGrantBasicAllowance(sponsor.FormattedAddress(), env.icaAddr, denom, math.NewInt(icaFeeAllowance))))
+	require.Equal(t, math.NewInt(icaFeeAllowance).String(),
+		queryFeeAllowance(t, ctx, lumera, sponsor.FormattedAddress(), env.icaAddr, denom).String())
+
+	sponsorBefore, err := lumera.GetBalance(ctx, sponsor.FormattedAddress(), denom)
+	require.NoError(t, err)
+
+	// requireUncharged checks that nobody paid for the ICA's packets.
+	requireUncharged := func(t *testing.T) {
+		t.Helper()
+		ica, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+		require.True(t, ica.IsZero(), "ICA should still hold no %s, has %s", denom, ica)
+		require.Equal(t, math.NewInt(icaFeeAllowance).String(),
+			queryFeeAllowance(t, ctx, lumera, sponsor.FormattedAddress(), env.icaAddr, denom).String(),
+			"ICA execution must not consume the allowance")
+		sponsorAfter, err := lumera.GetBalance(ctx, sponsor.FormattedAddress(), denom)
+		require.NoError(t, err)
+		require.Equal(t, sponsorBefore.String(), sponsorAfter.String(), "sponsor must not be charged")
+	}
+
+	t.Run("ExecutesWithoutGasFees", func(t *testing.T) {
+		port, seq := sendICAPacket(t, ctx, env, generateICAPacket(t, ctx, lumera,
+			msgSetWithdrawAddress(env.icaAddr, sponsor.FormattedAddress())))
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.True(t, ack.Success(), "ICA MsgSetWithdrawAddress failed: %s", ack.Error)
+		requireUncharged(t)
+	})
+
+	t.Run("ActionFeeNotCovered", func(t *testing.T) {
+		port, seq := sendICAPacket(t, ctx, env, buildCascadePacket(t, ctx, env))
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.False(t, ack.Success(), "an unfunded ICA must not pay the action fee through a fee grant")
+		// The cascade fee is a bank send from the ICA, which holds nothing.
+		require.Contains(t, ack.Error, sdkerrors.ErrInsufficientFunds.Error(),
+			"packet should fail on the unpaid action fee")
+		require.Empty(t, actionsByCreator(t, ctx, lumera, env.icaAddr), "no action may be created")
+		requireUncharged(t)
+	})
+}
+
+// queryFeeAllowance returns the remaining spend limit in denom of granter's
+// basic allowance for grantee.
+func queryFeeAllowance(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, granter, grantee, denom string) math.Int {
+	t.Helper()
+	var resp struct {
+		Allowance struct {
+			Allowance struct {
+				Type       string `json:"@type"`
+				SpendLimit []struct {
+					Denom  string `json:"denom"`
+					Amount string `json:"amount"`
+				} `json:"spend_limit"`
+			} `json:"allowance"`
+		} `json:"allowance"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "feegrant", "grant", granter, grantee))
+	allowance := resp.Allowance.Allowance
+	require.Equal(t, "/cosmos.feegrant.v1beta1.BasicAllowance", allowance.Type)
+	for _, c := range allowance.SpendLimit {
+		if c.Denom == denom {
+			amount, ok := math.NewIntFromString(c.Amount)
+			require.True(t, ok, "invalid spend limit %q", c.Amount)
+			return amount
+		}
+	}
+	return math.ZeroInt()
+}
+
+// msgGrantBasicAllowance lets grantee spend up to spendLimit of denom of
+// granter's balance on fees, with no expiration.
+func msgGrantBasicAllowance(granter, grantee, denom string, spendLimit math.Int) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":   "/cosmos.feegrant.v1beta1.MsgGrantAllowance",
+		"granter": granter,
+		"grantee": grantee,
+		"allowance": map[string]interface{}{
+			"@type":      "/cosmos.feegrant.v1beta1.BasicAllowance",
+			"spendLimit": []map[string]string{{"denom": denom, "amount": spendLimit.String()}},
+		},
+	}
+}
+
+func msgSetWithdrawAddress(delegator, withdrawAddr string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/cosmos.distribution.v1beta1.MsgSetWithdrawAddress",
+		"delegatorAddress": delegator,
+		"withdrawAddress":  withdrawAddr,
+	}
+}
@@ -0,0 +1,105 @@
+// ica_multi_controller_test.go — Several controller chains on one Lumera
+// host. Each controller (Osmosis and Gaia unless ICA_CONTROLLERS says
+// otherwise) gets its own connection and interchain account, the first one a
+// second account for another owner, and all of them request actions in
+// parallel. Every (connection, owner) pair must map to its own ICA, and every
+// action must be credited to the ICA whose packet created it.
+//
+// Test topology:
+//
+//	Osmosis (controller) ──IBC──▶ Lumera (host) ◀──IBC── Gaia (controller)
+package interchaintest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMultiControllerICA connects every controller chain in ICA_CONTROLLERS
+// (a comma-separated list of "name" or "name=version", default
+// DefaultMultiControllers) to a single Lumera host and drives an ICA from
+// each concurrently.
+func TestMultiControllerICA(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-controller ICA e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	controllers, err := multiControllersFromEnv()
+	require.NoError(t, err)
+
+	t.Logf("Testing with Lumera %s (local image: %v), controllers %v", version, useLocal, controllers)
+
+	envs := newMultiControllerICATestEnv(t, ctx, GetLumeraChainConfig(version, useLocal), controllers...)
+	// A second owner on the first connection: same controller port prefix
+	// and connection, different owner.
+	envs = append(envs, envs[0].withNewOwner(t, ctx))
+
+	for _, env := range envs {
+		registerICA(t, ctx, env)
+		fundICA(t, ctx, env.lumera, env.icaAddr)
+	}
+
+	t.Run("DistinctAddresses", func(t *testing.T) {
+		seen := make(map[string]string)
+		for _, env := range envs {
+			name := icaOwnerName(env)
+			require.NotEmpty(t, env.icaAddr, name)
+			if other, ok := seen[env.icaAddr]; ok {
+				require.Failf(t, "ICA address reused", "%s and %s both map to %s", other, name, env.icaAddr)
+			}
+			seen[env.icaAddr] = name
+
+			// The account is keyed by connection and owner: the same owner
+			// has none over another controller's connection.
+			for _, other := range envs {
+				if other.controller == env.controller {
+					continue
+				}
+				addr, err := tryQueryICAAddress(ctx, other.controller, other.connectionID, env.user.FormattedAddress())
+				require.True(t, err != nil || addr == "", "%s has an ICA on %s: %s", name, other.controller.Config().Name, addr)
+			}
+		}
+	})
+
+	// Each ICA requests a different number of actions, so credit going to
+	// the wrong account shows up in the per-creator counts.
+	packets := make([][]byte, len(envs))
+	for i, env := range envs {
+		packets[i] = buildCascadeBatchPacket(t, ctx, env, i+1)
+	}
+	actionsBefore := len(listActions(t, ctx, envs[0].lumera))
+
+	t.Run("ConcurrentActions", func(t *testing.T) {
+		for i, env := range envs {
+			t.Run(icaOwnerName(env), func(t *testing.T) {
+				t.Parallel()
+				port, seq := sendICAPacket(t, ctx, env, packets[i])
+				ack := queryICAAck(t, ctx, env.lumera, port, seq)
+				require.True(t, ack.Success(), "ICA MsgRequestAction from %s failed: %s", icaOwnerName(env), ack.Error)
+			})
+		}
+	})
+
+	t.Run("ActionsAttributed", func(t *testing.T) {
+		total := 0
+		for i, env := range envs {
+			actions := actionsByCreator(t, ctx, env.lumera, env.icaAddr)
+			require.Len(t, actions, i+1, "actions credited to %s (%s)", icaOwnerName(env), env.icaAddr)
+			for _, a := range actions {
+				require.Equal(t, "ACTION_TYPE_CASCADE", a.ActionType)
+			}
+			total += len(actions)
+		}
+		require.Len(t, listActions(t, ctx, envs[0].lumera), actionsBefore+total, "actions created by other accounts")
+	})
+}
+
+// icaOwnerName identifies an ICA by its controller chain and owner address.
+func icaOwnerName(env *icaTestEnv) string {
+	return fmt.Sprintf("%s:%s", env.controller.Config().Name, env.user.FormattedAddress())
+}
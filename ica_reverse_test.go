@@ -0,0 +1,197 @@
+// ica_reverse_test.go — Lumera as ICS-27 controller, Osmosis as host. A Lumera
+// account registers an interchain account on Osmosis and uses it for a bank
+// send and a swap against a uosmo/LUME pool, proving that Lumera's controller
+// module is enabled in genesis and works end to end.
+//
+// Test topology:
+//
+//	Lumera (controller) ──IBC──▶ Osmosis (host)
+//	   │                              │
+//	   │  1. register ICA             │
+//	   │  2. send-tx (ICA packet) ──▶ │  3. execute MsgSend / MsgSwapExactAmountIn
+package interchaintest_test
+
+import (
+	"context"
+	"testing"
+
+	"cosmossdk.io/math"
+
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// osmosisPoolDeposit of each asset seeds the uosmo/LUME pool.
+	osmosisPoolDeposit = 1_000_000_000
+	// osmosisSendAmount is sent by the ICA to a fresh Osmosis account.
+	osmosisSendAmount = 2_000_000
+	// osmosisSwapAmount is the uosmo the ICA swaps for LUME.
+	osmosisSwapAmount = 1_000_000
+)
+
+// TestLumeraControllerOsmosisHost controls an Osmosis interchain account from
+// Lumera. The Osmosis version is the first of OSMOSIS_VERSIONS, or the
+// registry default.
+func TestLumeraControllerOsmosisHost(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping Lumera controller e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	osmosis, osmosisVersion, err := osmosisFromEnv()
+	require.NoError(t, err)
+
+	t.Logf("Testing Lumera %s (local image: %v) controlling an ICA on Osmosis %s", version, useLocal, osmosisVersion)
+
+	lumeraConfig := GetLumeraChainConfig(version, useLocal, WithICAControllerEnabled(true))
+	hostEnv := newMultiControllerICATestEnv(t, ctx, lumeraConfig, controllerSpec{
+		chain:   osmosis,
+		version: osmosisVersion,
+		genesisKVs: []cosmos.GenesisKV{
+			cosmos.NewGenesisKV("app_state.interchainaccounts.host_genesis_state.params.host_enabled", true),
+			cosmos.NewGenesisKV("app_state.interchainaccounts.host_genesis_state.params.allow_messages", []string{"*"}),
+		},
+	})[0]
+	env := lumeraControllerEnv(t, ctx, hostEnv)
+	lumera, host := env.lumera, env.host
+	denom := host.Config().Denom
+
+	t.Run("ControllerEnabled", func(t *testing.T) {
+		var controllerParams struct {
+			Params struct {
+				ControllerEnabled bool `json:"controller_enabled"`
+			} `json:"params"`
+		}
+		require.NoError(t, queryJSON(ctx, lumera, &controllerParams, "interchain-accounts", "controller", "params"))
+		require.True(t, controllerParams.Params.ControllerEnabled, "Lumera ICA controller should be enabled in genesis")
+
+		var hostParams struct {
+			Params struct {
+				HostEnabled bool `json:"host_enabled"`
+			} `json:"params"`
+		}
+		require.NoError(t, queryJSON(ctx, host, &hostParams, "interchain-accounts", "host", "params"))
+		require.True(t, hostParams.Params.HostEnabled, "Osmosis ICA host should be enabled")
+	})
+
+	// Recent ibc-go controllers open unordered channels by default, which
+	// hosts before ibc-go v8.1 reject.
+	registerICA(t, ctx, env, "--ordering", "ORDER_ORDERED")
+	fundICA(t, ctx, host, env.icaAddr)
+
+	t.Run("BankSend", func(t *testing.T) {
+		recipient, err := host.BuildWallet(ctx, "ica-recipient", "")
+		require.NoError(t, err)
+		icaBefore, err := host.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+
+		msg := msgSend(env.icaAddr, recipient.FormattedAddress(), denom, math.NewInt(osmosisSendAmount))
+		port, seq := sendICAPacket(t, ctx, env, generateICAPacket(t, ctx, host, msg))
+		ack := queryICAAck(t, ctx, host, port, seq)
+		require.True(t, ack.Success(), "ICA MsgSend failed: %s", ack.Error)
+
+		received, err := host.GetBalance(ctx, recipient.FormattedAddress(), denom)
+		require.NoError(t, err)
+		require.Equal(t, math.NewInt(osmosisSendAmount).String(), received.String(), "recipient should receive the ICA's send")
+		icaAfter, err := host.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+		require.Equal(t, icaBefore.SubRaw(osmosisSendAmount).String(), icaAfter.String())
+	})
+
+	t.Run("Swap", func(t *testing.T) {
+		lumeDenom, poolID := createLumePool(t, ctx, hostEnv)
+
+		osmoBefore, err := host.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+
+		msg := msgSwapExactAmountIn(env.icaAddr, poolID, lumeDenom, denom, math.NewInt(osmosisSwapAmount))
+		port, seq := sendICAPacket(t, ctx, env, generateICAPacket(t, ctx, host, msg))
+		ack := queryICAAck(t, ctx, host, port, seq)
+		require.True(t, ack.Success(), "ICA MsgSwapExactAmountIn failed: %s", ack.Error)
+
+		lume, err := host.GetBalance(ctx, env.icaAddr, lumeDenom)
+		require.NoError(t, err)
+		require.True(t, lume.IsPositive(), "ICA should hold LUME after the swap")
+		osmoAfter, err := host.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+		require.Equal(t, osmoBefore.SubRaw(osmosisSwapAmount).String(), osmoAfter.String())
+		t.Logf("ICA swapped %d%s for %s%s in pool %s", osmosisSwapAmount, denom, lume, lumeDenom, poolID)
+	})
+}
+
+// lumeraControllerEnv returns an env over env's connection with the roles
+// swapped: a funded Lumera user controls an ICA on env.controller.
+func lumeraControllerEnv(t *testing.T, ctx context.Context, env *icaTestEnv) *icaTestEnv {
+	t.Helper()
+	connections, err := env.relayer.GetConnections(ctx, env.eRep, env.lumera.Config().ChainID)
+	require.NoError(t, err)
+	require.NotEmpty(t, connections)
+
+	owner := interchaintest.GetAndFundTestUsers(t, ctx, "ica-owner", math.NewInt(10_000_000_000), env.lumera)[0]
+	return &icaTestEnv{
+		controller:   env.lumera,
+		lumera:       env.lumera,
+		host:         env.controller,
+		relayer:      env.relayer,
+		eRep:         env.eRep,
+		docker:       env.docker,
+		path:         env.path,
+		user:         owner,
+		connectionID: connections[0].ID,
+	}
+}
+
+// createLumePool transfers LUME from Lumera to env.user on env.controller
+// (Osmosis) over ICS-20, and creates a balanced uosmo/LUME pool from it. It
+// returns the IBC denom of LUME on Osmosis and the pool ID.
+func createLumePool(t *testing.T, ctx context.Context, env *icaTestEnv) (string, string) {
+	t.Helper()
+	lumera, osmosis := env.lumera, env.controller
+	lumeraDenom, osmoDenom := lumera.Config().Denom, osmosis.Config().Denom
+
+	sender := interchaintest.GetAndFundTestUsers(t, ctx, "lp", math.NewInt(2*osmosisPoolDeposit), lumera)[0]
+	channel := channelByPort(t, ctx, env, lumera, transferPort)
+	sendTransfer(t, ctx, env, lumera, channel, sender, env.user.FormattedAddress(), lumeraDenom, math.NewInt(osmosisPoolDeposit))
+	lumeDenom := ibcDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, lumeraDenom)
+	waitForBalance(t, ctx, osmosis, env.user.FormattedAddress(), lumeDenom, math.NewInt(osmosisPoolDeposit))
+
+	res := broadcastMsgs(t, ctx, osmosis, env.user.KeyName(), map[string]interface{}{
+		"@type":      "/osmosis.gamm.poolmodels.balancer.v1beta1.MsgCreateBalancerPool",
+		"sender":     env.user.FormattedAddress(),
+		"poolParams": map[string]string{"swapFee": "0.003", "exitFee": "0"},
+		"poolAssets": []map[string]interface{}{
+			{"token": map[string]string{"denom": osmoDenom, "amount": math.NewInt(osmosisPoolDeposit).String()}, "weight": "1"},
+			{"token": map[string]string{"denom": lumeDenom, "amount": math.NewInt(osmosisPoolDeposit).String()}, "weight": "1"},
+		},
+		"futurePoolGovernor": "",
+	})
+	requireTxSuccess(t, res)
+	poolID, ok := res.eventAttribute("pool_created", "pool_id")
+	require.True(t, ok, "create pool should emit pool_created")
+	t.Logf("Created uosmo/LUME pool %s (LUME is %s on Osmosis)", poolID, lumeDenom)
+	return lumeDenom, poolID
+}
+
+func msgSend(from, to, denom string, amount math.Int) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":       "/cosmos.bank.v1beta1.MsgSend",
+		"fromAddress": from,
+		"toAddress":   to,
+		"amount":      []map[string]string{{"denom": denom, "amount": amount.String()}},
+	}
+}
+
+// msgSwapExactAmountIn swaps amount of denomIn for at least 1 of denomOut in
+// a single pool.
+func msgSwapExactAmountIn(sender, poolID, denomOut, denomIn string, amount math.Int) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":             "/osmosis.poolmanager.v1beta1.MsgSwapExactAmountIn",
+		"sender":            sender,
+		"routes":            []map[string]string{{"poolId": poolID, "tokenOutDenom": denomOut}},
+		"tokenIn":           map[string]string{"denom": denomIn, "amount": amount.String()},
+		"tokenOutMinAmount": "1",
+	}
+}
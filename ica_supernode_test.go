@@ -0,0 +1,187 @@
+// ica_supernode_test.go — Supernode management from Osmosis through an
+// interchain account on Lumera. Covers staking via ICA and the supernode
+// authorization rules as seen by host execution: the ICA may only register or
+// update a supernode for a validator it operates, not for one it merely
+// delegates to or serves as supernode account for.
+package interchaintest_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"cosmossdk.io/math"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// icaDelegation is delegated by the ICA to the genesis validator.
+	icaDelegation = 1_000_000_000 // 1,000 LUME
+	// icaSelfDelegation bonds the ICA-operated validator.
+	icaSelfDelegation = 1_000_000_000 // 1,000 LUME
+)
+
+// TestICASupernodeOperations manages supernodes on Lumera from Osmosis via ICA.
+func TestICASupernodeOperations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA supernode e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	lumeraConfig := GetLumeraChainConfig(version, useLocal,
+		WithSupernodeMinimumStake(math.NewInt(supernodeTestMinStake)),
+	)
+
+	t.Logf("Testing ICA supernode operations on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, lumeraConfig)
+	registerICA(t, ctx, env)
+	fundICA(t, ctx, env.lumera, env.icaAddr)
+
+	lumera := env.lumera
+	genesisValoper, err := lumera.GetNode().KeyBech32(ctx, "validator", "val")
+	require.NoError(t, err)
+
+	t.Run("DelegateViaICA", func(t *testing.T) {
+		packet := generateICAPacket(t, ctx, lumera, msgDelegate(env.icaAddr, genesisValoper, math.NewInt(icaDelegation)))
+		port, seq := sendICAPacket(t, ctx, env, packet)
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.True(t, ack.Success(), "ICA delegation failed: %s", ack.Error)
+
+		var resp struct {
+			DelegationResponse struct {
+				Balance struct {
+					Amount string `json:"amount"`
+				} `json:"balance"`
+			} `json:"delegation_response"`
+		}
+		require.NoError(t, queryJSON(ctx, lumera, &resp, "staking", "delegation", env.icaAddr, genesisValoper))
+		require.Equal(t, math.NewInt(icaDelegation).String(), resp.DelegationResponse.Balance.Amount)
+	})
+
+	// A delegator is not the validator operator: registering a supernode for
+	// someone else's validator must be rejected by the host.
+	t.Run("RegisterForForeignValidatorRejected", func(t *testing.T) {
+		packet := generateICAPacket(t, ctx, lumera,
+			msgRegisterSupernode(env.icaAddr, genesisValoper, supernodeTestIP, env.icaAddr, supernodeTestP2PPort))
+		port, seq := sendICAPacket(t, ctx, env, packet)
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.False(t, ack.Success(), "ICA must not register a supernode for a validator it does not operate")
+
+		_, err := tryQuerySupernode(ctx, lumera, genesisValoper)
+		require.Error(t, err, "no supernode should exist for the genesis validator")
+	})
+
+	// The validator registers a supernode with the ICA as its supernode
+	// account. That role does not grant the ICA operator rights.
+	t.Run("ICAAsSupernodeAccountCannotUpdate", func(t *testing.T) {
+		validatorAcc := validatorAccountAddress(t, ctx, lumera)
+		res := broadcastMsgs(t, ctx, lumera, "validator",
+			msgRegisterSupernode(validatorAcc, genesisValoper, supernodeTestIP, env.icaAddr, supernodeTestP2PPort))
+		requireTxSuccess(t, res)
+		require.Equal(t, env.icaAddr, querySupernode(t, ctx, lumera, genesisValoper).SupernodeAccount)
+
+		packet := generateICAPacket(t, ctx, lumera,
+			msgUpdateSupernode(env.icaAddr, genesisValoper, "10.0.0.99", "hijack", env.icaAddr, supernodeTestP2PPort))
+		port, seq := sendICAPacket(t, ctx, env, packet)
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.False(t, ack.Success(), "supernode account must not be able to update the supernode")
+
+		sn := querySupernode(t, ctx, lumera, genesisValoper)
+		require.Equal(t, supernodeTestIP, sn.currentIPAddress(), "supernode IP must be unchanged")
+		require.NotEqual(t, "hijack", sn.Note)
+	})
+
+	// Once the ICA operates its own validator it can register and update the
+	// supernode for it entirely from Osmosis.
+	t.Run("ICAAsOperator", func(t *testing.T) {
+		testICAOperatedSupernode(t, ctx, env)
+	})
+}
+
+func testICAOperatedSupernode(t *testing.T, ctx context.Context, env *icaTestEnv) {
+	lumera := env.lumera
+	icaValoper := valoperFromAccount(t, env.icaAddr)
+
+	packet := generateICAPacket(t, ctx, lumera, msgCreateValidator(t, icaValoper, math.NewInt(icaSelfDelegation)))
+	port, seq := sendICAPacket(t, ctx, env, packet)
+	ack := queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA create-validator failed: %s", ack.Error)
+	t.Logf("ICA operates validator %s", icaValoper)
+
+	packet = generateICAPacket(t, ctx, lumera,
+		msgRegisterSupernode(env.icaAddr, icaValoper, supernodeTestIP, env.icaAddr, supernodeTestP2PPort))
+	port, seq = sendICAPacket(t, ctx, env, packet)
+	ack = queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA supernode registration failed: %s", ack.Error)
+
+	sn := querySupernode(t, ctx, lumera, icaValoper)
+	require.Equal(t, icaValoper, sn.ValidatorAddress)
+	require.Equal(t, env.icaAddr, sn.SupernodeAccount)
+	require.Equal(t, supernodeStateActive, sn.currentState())
+
+	const newIP = "10.0.0.42"
+	packet = generateICAPacket(t, ctx, lumera,
+		msgUpdateSupernode(env.icaAddr, icaValoper, newIP, "managed from osmosis", env.icaAddr, supernodeTestP2PPort))
+	port, seq = sendICAPacket(t, ctx, env, packet)
+	ack = queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA supernode update failed: %s", ack.Error)
+
+	sn = querySupernode(t, ctx, lumera, icaValoper)
+	require.Equal(t, newIP, sn.currentIPAddress())
+	require.Equal(t, "managed from osmosis", sn.Note)
+}
+
+// valoperFromAccount re-encodes an account address with the Lumera
+// validator operator prefix.
+func valoperFromAccount(t *testing.T, addr string) string {
+	t.Helper()
+	_, bz, err := bech32.DecodeAndConvert(addr)
+	require.NoError(t, err)
+	valoper, err := bech32.ConvertAndEncode(LumeraConfig.Bech32Prefix+"valoper", bz)
+	require.NoError(t, err)
+	return valoper
+}
+
+func msgDelegate(delegator, valoper string, amount math.Int) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/cosmos.staking.v1beta1.MsgDelegate",
+		"delegatorAddress": delegator,
+		"validatorAddress": valoper,
+		"amount":           map[string]string{"denom": LumeraConfig.Denom, "amount": amount.String()},
+	}
+}
+
+// msgCreateValidator creates a validator operated by valoper with a fresh
+// consensus key. The validator never signs blocks; it only needs to exist
+// for the duration of the test.
+func msgCreateValidator(t *testing.T, valoper string, selfDelegation math.Int) map[string]interface{} {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return map[string]interface{}{
+		"@type": "/cosmos.staking.v1beta1.MsgCreateValidator",
+		"description": map[string]string{
+			"moniker": "ica-validator",
+		},
+		"commission": map[string]string{
+			"rate":          "0.100000000000000000",
+			"maxRate":       "0.200000000000000000",
+			"maxChangeRate": "0.010000000000000000",
+		},
+		"minSelfDelegation": "1",
+		"validatorAddress":  valoper,
+		"pubkey": map[string]string{
+			"@type": "/cosmos.crypto.ed25519.PubKey",
+			"key":   base64.StdEncoding.EncodeToString(pub),
+		},
+		"value": map[string]string{"denom": LumeraConfig.Denom, "amount": selfDelegation.String()},
+	}
+}
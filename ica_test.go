@@ -1,5 +1,6 @@
 // ica_test.go — End-to-end test for ICS-27 (Interchain Accounts) between Osmosis
-// and Lumera. Proves that a user on Osmosis (controller chain) can register an
+// (or another registered controller chain, see controllers.go) and Lumera.
+// Proves that a user on Osmosis (controller chain) can register an
 // interchain account on Lumera (host chain) and use it to submit a cascade
 // storage action (MsgRequestAction) — the full cross-chain flow.
 //
@@ -32,6 +33,7 @@ import (
 	"cosmossdk.io/math"
 
 	"github.com/cosmos/go-bip39"
+	"github.com/docker/docker/client"
 
 	"github.com/strangelove-ventures/interchaintest/v8"
 	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
@@ -43,10 +45,8 @@ import (
 	"go.uber.org/zap/zaptest"
 )
 
-// ibcPath is the relayer path name linking Osmosis and Lumera.
-const ibcPath = "osmo-lumera"
-
-// TestOsmosisLumeraICA spins up Osmosis + Lumera in Docker, connects them via
+// TestOsmosisLumeraICA spins up the controller chain (Osmosis unless
+// ICA_CONTROLLER says otherwise) + Lumera in Docker, connects them via
 // IBC, registers an interchain account, and executes a cascade action through it.
 func TestOsmosisLumeraICA(t *testing.T) {
 	if testing.Short() {
@@ -54,33 +54,138 @@ func TestOsmosisLumeraICA(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	rep := testreporter.NewNopReporter()
-	eRep := rep.RelayerExecReporter(t)
 
-	client, network := interchaintest.DockerSetup(t)
+	// Choose Lumera and controller versions and whether to use a local image.
+	// Override via LUMERA_VERSION / LUMERA_VERSIONS, ICA_CONTROLLER and
+	// ICA_CONTROLLER_VERSIONS (defaults defined in Makefile /
+	// DefaultLumeraVersion / the controller registry).
+	versions, useLocal := lumeraVersionsFromEnv(t)
+	controller, controllerVersions, err := controllerFromEnv()
+	require.NoError(t, err)
+
+	matrix := newCompatMatrix(t, controller.Name+" ⇄ Lumera ICA")
+	for _, version := range versions {
+		for _, controllerVersion := range controllerVersions {
+			pair := compatPair{Lumera: version, Controller: controller.Name, ControllerVersion: controllerVersion}
+			matrix.run(t, pair, func(t *testing.T) {
+				lumeraConfig := GetLumeraChainConfig(version, useLocal)
+
+				t.Logf("Testing with Lumera %s (local image: %v), %s %s", version, useLocal, controller.Name, controllerVersion)
 
-	// Choose Lumera version and whether to use local image
-	// Override via LUMERA_VERSION env var (default defined in Makefile / DefaultLumeraVersion)
-	version := DefaultLumeraVersion
-	if v := os.Getenv("LUMERA_VERSION"); v != "" {
-		version = v
+				env := newICATestEnvWithController(t, ctx, controller, controllerVersion, lumeraConfig)
+
+				// ── Sub-tests ──
+				t.Run("RegisterICA", func(t *testing.T) {
+					testRegisterICA(t, ctx, env, icaFundingBankSend)
+				})
+			})
+		}
+	}
+}
+
+// TestOsmosisLumeraICAFundedByTransfer is the ICA flow as a user who starts
+// with nothing on Lumera sees it: the ICA is funded with LUME held on the
+// controller chain, sent over ICS-20, instead of by a Lumera-side funder.
+func TestOsmosisLumeraICAFundedByTransfer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA e2e test in short mode")
 	}
-	useLocal := os.Getenv("USE_LOCAL_IMAGE") == "true"
 
-	lumeraConfig := GetLumeraChainConfig(version, useLocal)
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
 
-	t.Logf("Testing with Lumera %s (local image: %v)", version, useLocal)
+	t.Logf("Testing ICA funded by transfer with Lumera %s (local image: %v)", version, useLocal)
 
-	// ── Build chains ──
-	cf := interchaintest.NewBuiltinChainFactory(zaptest.NewLogger(t), []*interchaintest.ChainSpec{
-		{ChainConfig: OsmosisConfig, NumValidators: &[]int{1}[0], NumFullNodes: &[]int{0}[0]},
-		{ChainConfig: lumeraConfig, NumValidators: &[]int{1}[0], NumFullNodes: &[]int{0}[0]},
+	env := newICATestEnv(t, ctx, GetLumeraChainConfig(version, useLocal))
+	t.Run("RegisterICA", func(t *testing.T) {
+		testRegisterICA(t, ctx, env, icaFundingTransfer)
 	})
+}
+
+// icaTestEnv is a running controller ⇄ Lumera network with a funded controller
+// user, shared by the ICA scenarios. icaAddr is set once registerICA succeeds.
+type icaTestEnv struct {
+	controller, lumera *cosmos.CosmosChain
+	// host executes the ICA's messages: lumera, except when Lumera is the
+	// controller (see ica_reverse_test.go).
+	host    *cosmos.CosmosChain
+	relayer ibc.Relayer
+	eRep    *testreporter.RelayerExecReporter
+	docker  *client.Client
+	// path is the relayer path linking controller and Lumera.
+	path string
+
+	user         ibc.Wallet
+	mnemonic     string
+	connectionID string
+	icaAddr      string
+}
+
+// icaPath returns the relayer path name linking controller to Lumera.
+func icaPath(controller string) string {
+	return controller + "-lumera"
+}
+
+// newICATestEnv builds the controller chain selected by ICA_CONTROLLER (at
+// its first version) and Lumera (with the given config), links them with a
+// relayer and funds a controller user with a known mnemonic.
+func newICATestEnv(t *testing.T, ctx context.Context, lumeraConfig ibc.ChainConfig) *icaTestEnv {
+	t.Helper()
+	controller, versions, err := controllerFromEnv()
+	require.NoError(t, err)
+	return newICATestEnvWithController(t, ctx, controller, versions[0], lumeraConfig)
+}
+
+// newICATestEnvWithController is newICATestEnv with an explicit controller
+// chain and image version.
+func newICATestEnvWithController(
+	t *testing.T, ctx context.Context,
+	controllerChain ControllerChain, controllerVersion string,
+	lumeraConfig ibc.ChainConfig,
+) *icaTestEnv {
+	t.Helper()
+	return newMultiControllerICATestEnv(t, ctx, lumeraConfig, controllerSpec{chain: controllerChain, version: controllerVersion})[0]
+}
+
+// newMultiControllerICATestEnv builds Lumera (with the given config) and one
+// chain per controller spec, each linked to Lumera on its own relayer path
+// and connection. It returns one env per controller, in order; all of them
+// share the Lumera chain and the relayer.
+func newMultiControllerICATestEnv(
+	t *testing.T, ctx context.Context,
+	lumeraConfig ibc.ChainConfig,
+	controllers ...controllerSpec,
+) []*icaTestEnv {
+	t.Helper()
+	specs := make([]*interchaintest.ChainSpec, 0, len(controllers)+1)
+	for _, c := range controllers {
+		if c.chain.ICSConsumer {
+			t.Skipf("%s is unsupported: it only runs as an ICS consumer and this topology has no provider chain", c.chain.Name)
+		}
+		config := c.chain.ChainConfig(c.version)
+		if len(c.genesisKVs) > 0 {
+			config.ModifyGenesis = cosmos.ModifyGenesis(c.genesisKVs)
+		}
+		specs = append(specs, &interchaintest.ChainSpec{
+			ChainConfig: config, NumValidators: &[]int{1}[0], NumFullNodes: &[]int{0}[0],
+		})
+	}
+	specs = append(specs, &interchaintest.ChainSpec{
+		ChainConfig: lumeraConfig, NumValidators: &[]int{1}[0], NumFullNodes: &[]int{0}[0],
+	})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	client, network := interchaintest.DockerSetup(t)
+
+	// ── Build chains ──
+	cf := interchaintest.NewBuiltinChainFactory(zaptest.NewLogger(t), specs)
 
 	chains, err := cf.Chains(t.Name())
 	require.NoError(t, err)
 
-	osmosis, lumera := chains[0].(*cosmos.CosmosChain), chains[1].(*cosmos.CosmosChain)
+	lumera := chains[len(chains)-1].(*cosmos.CosmosChain)
 
 	// ── Build relayer ──
 	r := interchaintest.NewBuiltinRelayerFactory(
@@ -91,15 +196,20 @@ func TestOsmosisLumeraICA(t *testing.T) {
 
 	// ── Create interchain ──
 	ic := interchaintest.NewInterchain().
-		AddChain(osmosis).
 		AddChain(lumera).
-		AddRelayer(r, "relayer").
-		AddLink(interchaintest.InterchainLink{
-			Chain1:  osmosis,
-			Chain2:  lumera,
-			Relayer: r,
-			Path:    ibcPath,
-		})
+		AddRelayer(r, "relayer")
+	paths := make([]string, 0, len(controllers))
+	for i, c := range controllers {
+		path := icaPath(c.chain.Name)
+		ic = ic.AddChain(chains[i]).
+			AddLink(interchaintest.InterchainLink{
+				Chain1:  chains[i],
+				Chain2:  lumera,
+				Relayer: r,
+				Path:    path,
+			})
+		paths = append(paths, path)
+	}
 
 	require.NoError(t, ic.Build(ctx, eRep, interchaintest.InterchainBuildOptions{
 		TestName:  t.Name(),
@@ -109,16 +219,63 @@ func TestOsmosisLumeraICA(t *testing.T) {
 	t.Cleanup(func() { _ = ic.Close() })
 
 	// ── Start relayer ──
-	require.NoError(t, r.StartRelayer(ctx, eRep, ibcPath))
+	require.NoError(t, r.StartRelayer(ctx, eRep, paths...))
 	t.Cleanup(func() { _ = r.StopRelayer(ctx, eRep) })
 
-	// ── Get connection IDs ──
-	connections, err := r.GetConnections(ctx, eRep, osmosis.Config().ChainID)
+	envs := make([]*icaTestEnv, 0, len(controllers))
+	for i := range controllers {
+		controller := chains[i].(*cosmos.CosmosChain)
+
+		// ── Get connection IDs ──
+		connections, err := r.GetConnections(ctx, eRep, controller.Config().ChainID)
+		require.NoError(t, err)
+		require.NotEmpty(t, connections)
+
+		env := &icaTestEnv{
+			controller:   controller,
+			lumera:       lumera,
+			host:         lumera,
+			relayer:      r,
+			eRep:         eRep,
+			docker:       client,
+			path:         paths[i],
+			connectionID: connections[0].ID,
+		}
+		fundICAOwner(t, ctx, env)
+		envs = append(envs, env)
+	}
+	return envs
+}
+
+// channelByPort returns the first of chain's channels on port, as seen by
+// env's relayer.
+func channelByPort(t *testing.T, ctx context.Context, env *icaTestEnv, chain *cosmos.CosmosChain, port string) ibc.ChannelOutput {
+	t.Helper()
+	channels, err := env.relayer.GetChannels(ctx, env.eRep, chain.Config().ChainID)
 	require.NoError(t, err)
-	require.NotEmpty(t, connections)
-	osmosisConnectionID := connections[0].ID
+	for _, ch := range channels {
+		if ch.PortID == port {
+			return ch
+		}
+	}
+	require.FailNow(t, "no channel on port", "%s has no %s channel: %+v", chain.Config().Name, port, channels)
+	return ibc.ChannelOutput{}
+}
 
-	// ── Fund user on Osmosis ──
+// withNewOwner returns a copy of env with a fresh funded controller user, to
+// register a second interchain account over the same connection.
+func (env *icaTestEnv) withNewOwner(t *testing.T, ctx context.Context) *icaTestEnv {
+	t.Helper()
+	owner := *env
+	owner.icaAddr = ""
+	fundICAOwner(t, ctx, &owner)
+	return &owner
+}
+
+// fundICAOwner creates env.user, a funded controller user with a known
+// mnemonic.
+func fundICAOwner(t *testing.T, ctx context.Context, env *icaTestEnv) {
+	t.Helper()
 	// We generate a mnemonic (rather than letting interchaintest create one)
 	// because the same key must later be imported into the buildpacket tool's
 	// keyring to sign the cascade MsgRequestAction on behalf of the ICA.
@@ -127,41 +284,76 @@ func TestOsmosisLumeraICA(t *testing.T) {
 	mnemonic, err := bip39.NewMnemonic(entropy)
 	require.NoError(t, err)
 
-	osmosisUser, err := interchaintest.GetAndFundTestUserWithMnemonic(
-		ctx, "ica-user", mnemonic, math.NewInt(10_000_000_000), osmosis,
+	user, err := interchaintest.GetAndFundTestUserWithMnemonic(
+		ctx, "ica-user", mnemonic, math.NewInt(10_000_000_000), env.controller,
 	)
 	require.NoError(t, err)
+	env.user, env.mnemonic = user, mnemonic
+}
 
-	// ── Sub-tests ──
-	t.Run("RegisterICA", func(t *testing.T) {
-		testRegisterICA(t, ctx, osmosis, lumera, r, eRep, osmosisUser, osmosisConnectionID, mnemonic)
+func testRegisterICA(t *testing.T, ctx context.Context, env *icaTestEnv, funding icaFunding) {
+	registerICA(t, ctx, env)
+
+	// ── Step 3: Fund ICA ──
+	// The ICA address exists on Lumera but has no tokens. Fund it so it can
+	// pay for the MsgRequestAction later.
+	fundICAWith(t, ctx, env, funding)
+
+	// ── Step 4: Execute MsgRequestAction via ICA ──
+	t.Run("ExecuteAction", func(t *testing.T) {
+		testExecuteActionViaICA(t, ctx, env)
 	})
 }
 
-func testRegisterICA(
-	t *testing.T, ctx context.Context,
-	osmosis, lumera *cosmos.CosmosChain,
-	r ibc.Relayer, eRep *testreporter.RelayerExecReporter,
-	user ibc.Wallet, connectionID, mnemonic string,
-) {
-	// ── Step 1: Register ICA from Osmosis ──
+// registerICA registers an interchain account for env.user over
+// env.connectionID and waits until its host address is known. extraArgs are
+// passed through to the register command, e.g. "--ordering", "ORDER_ORDERED".
+func registerICA(t *testing.T, ctx context.Context, env *icaTestEnv, extraArgs ...string) {
+	t.Helper()
+	controller, user := env.controller, env.user
+	submitRegisterICA(t, ctx, env, extraArgs...)
+
+	// ── Step 2: Poll until the ICA address is registered ──
+	// The relayer completes the channel handshake asynchronously; poll instead
+	// of waiting a fixed number of blocks.
+	var icaAddr string
+	require.Eventually(t, func() bool {
+		addr, err := tryQueryICAAddress(ctx, controller, env.connectionID, user.FormattedAddress())
+		if err != nil || addr == "" {
+			return false
+		}
+		icaAddr = addr
+		return true
+	}, 2*time.Minute, 3*time.Second, "ICA address was not registered in time")
+	t.Logf("ICA address on %s: %s", env.host.Config().Name, icaAddr)
+	env.icaAddr = icaAddr
+}
+
+// submitRegisterICA broadcasts the register tx for env.user and checks that it
+// was accepted, without waiting for the channel handshake.
+func submitRegisterICA(t *testing.T, ctx context.Context, env *icaTestEnv, extraArgs ...string) {
+	t.Helper()
+	controller, user := env.controller, env.user
+
+	// ── Step 1: Register ICA from the controller chain ──
 	// This initiates the ICS-27 channel handshake. The relayer will complete
 	// INIT → TRY → ACK → CONFIRM asynchronously in the background.
 	registerCmd := []string{
-		osmosis.Config().Bin, "tx", "interchain-accounts", "controller",
-		"register", connectionID,
+		controller.Config().Bin, "tx", "interchain-accounts", "controller",
+		"register", env.connectionID,
 		"--from", user.KeyName(),
 		"--gas", "auto",
 		"--gas-adjustment", "1.5",
-		"--gas-prices", osmosis.Config().GasPrices,
+		"--gas-prices", controller.Config().GasPrices,
 		"-y",
-		"--chain-id", osmosis.Config().ChainID,
-		"--node", osmosis.GetRPCAddress(),
-		"--home", osmosis.HomeDir(),
+		"--chain-id", controller.Config().ChainID,
+		"--node", controller.GetRPCAddress(),
+		"--home", controller.HomeDir(),
 		"--keyring-backend", "test",
 		"--output", "json",
 	}
-	stdout, _, err := osmosis.Exec(ctx, registerCmd, nil)
+	registerCmd = append(registerCmd, extraArgs...)
+	stdout, _, err := controller.Exec(ctx, registerCmd, nil)
 	require.NoError(t, err)
 	t.Logf("Register ICA tx: %s", string(stdout))
 
@@ -172,30 +364,6 @@ func testRegisterICA(
 	}
 	require.NoError(t, json.Unmarshal(stdout, &registerTxResp), "failed to parse register ICA tx response: %s", string(stdout))
 	require.Equal(t, 0, registerTxResp.Code, "Register ICA tx failed: %s", registerTxResp.RawLog)
-
-	// ── Step 2: Poll until the ICA address is registered ──
-	// The relayer completes the channel handshake asynchronously; poll instead
-	// of waiting a fixed number of blocks.
-	var icaAddr string
-	require.Eventually(t, func() bool {
-		addr, err := tryQueryICAAddress(ctx, osmosis, connectionID, user.FormattedAddress())
-		if err != nil || addr == "" {
-			return false
-		}
-		icaAddr = addr
-		return true
-	}, 2*time.Minute, 3*time.Second, "ICA address was not registered in time")
-	t.Logf("ICA address on Lumera: %s", icaAddr)
-
-	// ── Step 3: Fund ICA via direct bank send on Lumera ──
-	// The ICA address exists on Lumera but has no tokens. We fund it directly
-	// on the host chain so it can pay gas for the MsgRequestAction later.
-	fundICA(t, ctx, lumera, icaAddr)
-
-	// ── Step 4: Execute MsgRequestAction via ICA ──
-	t.Run("ExecuteAction", func(t *testing.T) {
-		testExecuteActionViaICA(t, ctx, osmosis, lumera, r, eRep, user, connectionID, icaAddr, mnemonic)
-	})
 }
 
 // tryQueryICAAddress queries the ICA address, returning ("", err) if not yet available.
@@ -220,48 +388,110 @@ func tryQueryICAAddress(ctx context.Context, controller *cosmos.CosmosChain, con
 	return resp.Address, nil
 }
 
-// findICAChannel returns the channel ID for the ICA controller port on the given chain.
-func findICAChannel(t *testing.T, ctx context.Context, r ibc.Relayer, eRep *testreporter.RelayerExecReporter, chainID string) string {
+// icaFundingAmount is the LUME (in ulume) given to an ICA by fundICAWith.
+const icaFundingAmount = 10_000_000_000
+
+// icaFunding selects how fundICAWith gives an ICA its Lumera balance.
+type icaFunding int
+
+const (
+	// icaFundingBankSend bank-sends LUME from a Lumera-side funder (fundICA).
+	icaFundingBankSend icaFunding = iota
+	// icaFundingTransfer sends LUME held on the controller chain back to
+	// the ICA over ICS-20 (fundICAByTransfer), as a user who only has an
+	// account on the controller chain would.
+	icaFundingTransfer
+)
+
+// fundICAWith funds env.icaAddr on Lumera using the given strategy.
+func fundICAWith(t *testing.T, ctx context.Context, env *icaTestEnv, funding icaFunding) {
 	t.Helper()
-	channels, err := r.GetChannels(ctx, eRep, chainID)
-	require.NoError(t, err)
+	switch funding {
+	case icaFundingBankSend:
+		fundICA(t, ctx, env.lumera, env.icaAddr)
+	case icaFundingTransfer:
+		fundICAByTransfer(t, ctx, env)
+	default:
+		t.Fatalf("unknown ICA funding strategy %d", funding)
+	}
+}
 
-	for _, ch := range channels {
-		if strings.HasPrefix(ch.PortID, "icacontroller-") {
-			t.Logf("Found ICA channel: %s (port: %s)", ch.ChannelID, ch.PortID)
-			return ch.ChannelID
-		}
+// fundICAByTransfer funds env.icaAddr from env.user's LUME vouchers on the
+// controller chain. The vouchers are first sent to env.user from Lumera, as
+// if bought on the controller chain; then env.user transfers them to the ICA
+// and the test waits for Lumera to acknowledge the packet.
+func fundICAByTransfer(t *testing.T, ctx context.Context, env *icaTestEnv) {
+	t.Helper()
+	lumera, controller := env.lumera, env.controller
+	require.Same(t, lumera, env.host, "transfer funding needs Lumera as ICA host")
+	denom := lumera.Config().Denom
+	amount := math.NewInt(icaFundingAmount)
+
+	// The controller has a single transfer channel, to Lumera; Lumera may
+	// have one per controller.
+	controllerCh := channelByPort(t, ctx, env, controller, transferPort)
+	lumeraCh := ibc.ChannelOutput{
+		PortID:       controllerCh.Counterparty.PortID,
+		ChannelID:    controllerCh.Counterparty.ChannelID,
+		Counterparty: ibc.ChannelCounterparty{PortID: controllerCh.PortID, ChannelID: controllerCh.ChannelID},
 	}
-	t.Fatalf("no ICA controller channel found on chain %s (channels: %+v)", chainID, channels)
-	return ""
+	voucher := ibcDenom(controllerCh.PortID, controllerCh.ChannelID, denom)
+
+	// ── Seed env.user with LUME vouchers on the controller ──
+	vouchersBefore, err := controller.GetBalance(ctx, env.user.FormattedAddress(), voucher)
+	require.NoError(t, err)
+	seeder := interchaintest.GetAndFundTestUsers(t, ctx, "seeder", amount.MulRaw(2), lumera)[0]
+	sendTransfer(t, ctx, env, lumera, lumeraCh, seeder, env.user.FormattedAddress(), denom, amount)
+	waitForBalance(t, ctx, controller, env.user.FormattedAddress(), voucher, vouchersBefore.Add(amount))
+
+	// ── Transfer the vouchers to the ICA ──
+	icaBefore, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	tx := sendTransfer(t, ctx, env, controller, controllerCh, env.user, env.icaAddr, voucher, amount)
+
+	res := queryAckTx(t, ctx, lumera, tx.Packet.SourcePort, tx.Packet.SourceChannel, fmt.Sprint(tx.Packet.Sequence))
+	ack, _ := res.eventAttribute("write_acknowledgement", "packet_ack")
+	require.NotContains(t, ack, `"error"`, "transfer to ICA failed on Lumera")
+	t.Logf("Transfer to ICA acknowledged: %s", ack)
+
+	waitForBalance(t, ctx, lumera, env.icaAddr, denom, icaBefore.Add(amount))
+	vouchers, err := controller.GetBalance(ctx, env.user.FormattedAddress(), voucher)
+	require.NoError(t, err)
+	require.Equal(t, vouchersBefore.String(), vouchers.String(), "vouchers should leave the controller user")
 }
 
-// fundICA creates a funder wallet on Lumera and sends tokens directly to the
-// ICA address. This is a host-chain-local operation (no IBC involved) — it
-// simply ensures the ICA has enough gas to execute messages sent via ICS-27.
+// fundICA creates a funder wallet on the host chain (normally Lumera) and
+// sends tokens directly to the ICA address. This is a host-chain-local
+// operation (no IBC involved) that gives the ICA something to spend.
+//
+// The ICA never needs it for gas: the ICS-27 host module runs packet
+// messages straight through the message router, skipping the ante handler,
+// so host execution charges no fees. Tests rely on this to assert that the
+// ICA's balance changes by exactly the amount its messages move.
 func fundICA(
 	t *testing.T, ctx context.Context,
-	lumera *cosmos.CosmosChain,
+	host *cosmos.CosmosChain,
 	icaAddr string,
 ) {
-	lumeraUsers := interchaintest.GetAndFundTestUsers(t, ctx, "funder", math.NewInt(50_000_000_000), lumera)
-	funder := lumeraUsers[0]
+	hostUsers := interchaintest.GetAndFundTestUsers(t, ctx, "funder", math.NewInt(5*icaFundingAmount), host)
+	funder := hostUsers[0]
+	denom := host.Config().Denom
 
 	sendCmd := []string{
-		lumera.Config().Bin, "tx", "bank", "send",
-		funder.KeyName(), icaAddr, "10000000000ulume",
+		host.Config().Bin, "tx", "bank", "send",
+		funder.KeyName(), icaAddr, fmt.Sprint(icaFundingAmount) + denom,
 		"--from", funder.KeyName(),
 		"--gas", "auto",
 		"--gas-adjustment", "1.5",
-		"--gas-prices", lumera.Config().GasPrices,
+		"--gas-prices", host.Config().GasPrices,
 		"-y",
-		"--chain-id", lumera.Config().ChainID,
-		"--node", lumera.GetRPCAddress(),
-		"--home", lumera.HomeDir(),
+		"--chain-id", host.Config().ChainID,
+		"--node", host.GetRPCAddress(),
+		"--home", host.HomeDir(),
 		"--keyring-backend", "test",
 		"--output", "json",
 	}
-	stdout, _, err := lumera.Exec(ctx, sendCmd, nil)
+	stdout, _, err := host.Exec(ctx, sendCmd, nil)
 	require.NoError(t, err)
 
 	var sendResp struct {
@@ -271,14 +501,14 @@ func fundICA(
 	require.NoError(t, json.Unmarshal(stdout, &sendResp), "failed to parse bank send response: %s", string(stdout))
 	require.Equal(t, 0, sendResp.Code, "bank send to ICA failed: %s", sendResp.RawLog)
 
-	err = testutil.WaitForBlocks(ctx, 3, lumera)
+	err = testutil.WaitForBlocks(ctx, 3, host)
 	require.NoError(t, err)
 
 	// Verify balance
-	bal, err := lumera.GetBalance(ctx, icaAddr, "ulume")
+	bal, err := host.GetBalance(ctx, icaAddr, denom)
 	require.NoError(t, err)
-	require.True(t, bal.GT(math.ZeroInt()), "ICA should have LUME balance")
-	t.Logf("ICA balance: %s ulume", bal.String())
+	require.True(t, bal.GT(math.ZeroInt()), "ICA should have %s balance", denom)
+	t.Logf("ICA balance: %s %s", bal.String(), denom)
 }
 
 // buildpacketToolDir returns the absolute path to the tools/buildpacket directory.
@@ -319,87 +549,167 @@ func buildBuildpacketTool(t *testing.T) string {
 // the ICA channel. The flow is:
 //  1. Create a test file (simulates user data for cascade storage)
 //  2. Run the buildpacket tool to construct MsgRequestAction + wrap it in an ICA CosmosTx packet
-//  3. Submit the packet from Osmosis via "send-tx" (controller → host)
+//  3. Submit the packet from the controller chain via "send-tx" (controller → host)
 //  4. Wait for the relayer to deliver + execute the packet on Lumera
 //  5. Verify that the action was created on Lumera with the correct type
-func testExecuteActionViaICA(
-	t *testing.T, ctx context.Context,
-	osmosis, lumera *cosmos.CosmosChain,
-	r ibc.Relayer, eRep *testreporter.RelayerExecReporter,
-	user ibc.Wallet, connectionID, icaAddr, mnemonic string,
-) {
-	// ── Create a test file for cascade storage ──
-	tmpFile, err := os.CreateTemp("", "ica-cascade-test-*.bin")
-	require.NoError(t, err)
-	defer os.Remove(tmpFile.Name())
+func testExecuteActionViaICA(t *testing.T, ctx context.Context, env *icaTestEnv) {
+	packetJSON := buildCascadePacket(t, ctx, env)
 
-	testData := make([]byte, 1024) // 1 KB payload
-	for i := range testData {
-		testData[i] = byte(i % 256)
+	sendICAPacket(t, ctx, env, packetJSON)
+
+	// ── Verify action was created on Lumera ──
+	verifyActionCreated(t, ctx, env.lumera, env.icaAddr)
+}
+
+// buildCascadePacket runs the buildpacket tool against a fresh 1 KB test file
+// and returns ICA packet JSON wrapping a cascade MsgRequestAction whose
+// creator is env.icaAddr. extraArgs are passed through to the tool, e.g.
+// "--expiration", "2m".
+func buildCascadePacket(t *testing.T, ctx context.Context, env *icaTestEnv, extraArgs ...string) []byte {
+	t.Helper()
+	return buildCascadeBatchPacket(t, ctx, env, 1, extraArgs...)
+}
+
+// buildCascadeBatchPacket is like buildCascadePacket but packs n cascade
+// MsgRequestActions, each for a distinct file, into a single ICA packet.
+func buildCascadeBatchPacket(t *testing.T, ctx context.Context, env *icaTestEnv, n int, extraArgs ...string) []byte {
+	t.Helper()
+	args := []string{
+		"--mnemonic", env.mnemonic,
+		"--ica-address", env.icaAddr,
+		"--file", strings.Join(writeCascadeTestFiles(t, n), ","),
+		"--owner-hrp", env.controller.Config().Bech32Prefix,
 	}
-	_, err = tmpFile.Write(testData)
-	require.NoError(t, err)
-	require.NoError(t, tmpFile.Close())
+	packetJSON := runBuildpacket(t, ctx, env.lumera, append(args, extraArgs...)...)
+	require.NotEmpty(t, packetJSON, "buildpacket produced empty output")
+	t.Logf("ICA packet data: %s", string(packetJSON))
+	return packetJSON
+}
 
-	// ── Build the buildpacket helper tool ──
-	// This is a separate Go binary (tools/buildpacket/) that uses the Lumera
-	// SDK to build a real MsgRequestAction with cascade metadata. It must be
-	// a separate module because the Lumera SDK depends on ibc-go/v10, which
-	// conflicts with interchaintest's ibc-go/v8 at init() time.
+// cascadeRequestMsgs returns n cascade MsgRequestActions (proto-JSON, ready
+// for broadcastMsgs) created by the Lumera account derived from mnemonic.
+func cascadeRequestMsgs(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, mnemonic string, n int) []map[string]interface{} {
+	t.Helper()
+	out := runBuildpacket(t, ctx, lumera,
+		"--mnemonic", mnemonic,
+		"--file", strings.Join(writeCascadeTestFiles(t, n), ","),
+		"--output", "msgs",
+	)
+	var msgs []map[string]interface{}
+	require.NoError(t, json.Unmarshal(out, &msgs), "invalid buildpacket msgs output: %s", string(out))
+	require.Len(t, msgs, n)
+	return msgs
+}
+
+// writeCascadeTestFiles creates n distinct 1 KB payloads for cascade storage
+// and returns their paths. The files are removed when the test finishes.
+func writeCascadeTestFiles(t *testing.T, n int) []string {
+	t.Helper()
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		testData := make([]byte, 1024) // 1 KB payload
+		for j := range testData {
+			testData[j] = byte((i + j) % 256)
+		}
+		path := filepath.Join(t.TempDir(), fmt.Sprintf("ica-cascade-test-%d.bin", i))
+		require.NoError(t, os.WriteFile(path, testData, 0o644))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// runBuildpacket builds the buildpacket helper tool and runs it against the
+// Lumera host gRPC endpoint with args, returning its stdout.
+//
+// The tool is a separate Go binary (tools/buildpacket/) that uses the Lumera
+// SDK to build real MsgRequestActions with cascade metadata. It must be a
+// separate module because the Lumera SDK depends on ibc-go/v10, which
+// conflicts with interchaintest's ibc-go/v8 at init() time.
+func runBuildpacket(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, args ...string) []byte {
+	t.Helper()
 	toolBinary := buildBuildpacketTool(t)
 
-	// ── Get Lumera host gRPC address ──
-	// The buildpacket tool connects to Lumera's gRPC to query chain state
-	// (e.g. account sequence) needed to construct the message.
+	// The tool connects to Lumera's gRPC to query chain state (e.g. action
+	// params and supernodes) needed to construct messages.
 	grpcAddr := lumera.GetHostGRPCAddress()
 	require.NotEmpty(t, grpcAddr, "Lumera host gRPC address must be available")
 	grpcAddr = strings.Replace(grpcAddr, "0.0.0.0", "localhost", 1)
-	t.Logf("Lumera gRPC address: %s", grpcAddr)
 
-	// ── Run buildpacket tool to create ICA packet with real SDK data ──
 	toolCtx, toolCancel := context.WithTimeout(ctx, 2*time.Minute)
 	defer toolCancel()
-	toolCmd := exec.CommandContext(toolCtx, toolBinary,
-		"--mnemonic", mnemonic,
-		"--ica-address", icaAddr,
+	toolArgs := append([]string{
 		"--grpc-addr", grpcAddr,
 		"--chain-id", lumera.Config().ChainID,
-		"--file", tmpFile.Name(),
-		"--owner-hrp", "osmo",
-	)
+	}, args...)
+	toolCmd := exec.CommandContext(toolCtx, toolBinary, toolArgs...)
 	var stderrBuf strings.Builder
 	toolCmd.Stderr = &stderrBuf
-	packetJSON, err := toolCmd.Output()
+	out, err := toolCmd.Output()
 	t.Logf("buildpacket stderr:\n%s", stderrBuf.String())
 	require.NoError(t, err, "buildpacket tool failed: %s", stderrBuf.String())
-	require.NotEmpty(t, packetJSON, "buildpacket produced empty output")
-	t.Logf("ICA packet data: %s", string(packetJSON))
+	return out
+}
+
+// generateICAPacket builds ICA packet JSON for arbitrary msgs (proto-JSON
+// objects with an "@type" field) using the host's "generate-packet-data"
+// command, which knows every message type of its chain (lumerad for Lumera).
+//
+// A single msg is passed on its own rather than as an array, and without
+// --encoding (proto3 is the default), so that hosts on older ibc-go releases
+// accept it too.
+func generateICAPacket(t *testing.T, ctx context.Context, host *cosmos.CosmosChain, msgs ...map[string]interface{}) []byte {
+	t.Helper()
+	var msgsJSON []byte
+	var err error
+	if len(msgs) == 1 {
+		msgsJSON, err = json.Marshal(msgs[0])
+	} else {
+		msgsJSON, err = json.Marshal(msgs)
+	}
+	require.NoError(t, err)
+
+	cmd := []string{
+		host.Config().Bin, "tx", "interchain-accounts", "host",
+		"generate-packet-data", string(msgsJSON),
+	}
+	stdout, stderr, err := host.Exec(ctx, cmd, nil)
+	require.NoError(t, err, "generate-packet-data failed: %s", string(stderr))
+	t.Logf("ICA packet data: %s", string(stdout))
+	return stdout
+}
+
+// sendICAPacket submits packetJSON from env.user via "send-tx", waits for the
+// relayer to deliver it to the host, and returns the packet's source port and
+// sequence so callers can look up the acknowledgement.
+func sendICAPacket(t *testing.T, ctx context.Context, env *icaTestEnv, packetJSON []byte) (string, string) {
+	t.Helper()
+	controller, host, user := env.controller, env.host, env.user
 
-	// Write the ICA packet JSON into the Osmosis container's filesystem so
-	// the osmosisd CLI can read it as a file argument to send-tx.
-	packetFile := "ica_packet.json"
-	err = osmosis.GetNode().WriteFile(ctx, packetJSON, packetFile)
+	// Write the ICA packet JSON into the controller container's filesystem so
+	// its CLI can read it as a file argument to send-tx.
+	packetFile := fmt.Sprintf("ica_packet_%d.json", time.Now().UnixNano())
+	err := controller.GetNode().WriteFile(ctx, packetJSON, packetFile)
 	require.NoError(t, err)
 
-	// ── Send the ICA packet from Osmosis (controller) ──
-	// This broadcasts a tx on Osmosis that wraps our CosmosTx packet. The
+	// ── Send the ICA packet from the controller chain ──
+	// This broadcasts a tx on the controller that wraps our CosmosTx packet. The
 	// relayer will pick it up and deliver it to Lumera for execution.
-	packetFilePath := osmosis.HomeDir() + "/" + packetFile
+	packetFilePath := controller.HomeDir() + "/" + packetFile
 	sendTxCmd := []string{
-		osmosis.Config().Bin, "tx", "interchain-accounts", "controller",
-		"send-tx", connectionID, packetFilePath,
+		controller.Config().Bin, "tx", "interchain-accounts", "controller",
+		"send-tx", env.connectionID, packetFilePath,
 		"--from", user.KeyName(),
 		"--gas", "auto",
 		"--gas-adjustment", "2.0",
-		"--gas-prices", osmosis.Config().GasPrices,
+		"--gas-prices", controller.Config().GasPrices,
 		"-y",
-		"--chain-id", osmosis.Config().ChainID,
-		"--node", osmosis.GetRPCAddress(),
-		"--home", osmosis.HomeDir(),
+		"--chain-id", controller.Config().ChainID,
+		"--node", controller.GetRPCAddress(),
+		"--home", controller.HomeDir(),
 		"--keyring-backend", "test",
 		"--output", "json",
 	}
-	stdout, _, err := osmosis.Exec(ctx, sendTxCmd, nil)
+	stdout, _, err := controller.Exec(ctx, sendTxCmd, nil)
 	require.NoError(t, err)
 	t.Logf("ICA SendTx broadcast result: %s", string(stdout))
 
@@ -414,72 +724,107 @@ func testExecuteActionViaICA(
 	t.Logf("ICA SendTx tx hash: %s", broadcastResp.TxHash)
 
 	// Wait for tx to be included in a block, then check execution result
-	err = testutil.WaitForBlocks(ctx, 3, osmosis)
+	sendResult := waitForTx(t, ctx, controller, broadcastResp.TxHash)
+	t.Logf("ICA SendTx execution: code=%d raw_log=%s", sendResult.Code, sendResult.RawLog)
+	require.Equal(t, 0, sendResult.Code, "ICA SendTx execution failed: %s", sendResult.RawLog)
+
+	srcPort, ok := sendResult.eventAttribute("send_packet", "packet_src_port")
+	require.True(t, ok, "send-tx should emit send_packet")
+	srcChannel, _ := sendResult.eventAttribute("send_packet", "packet_src_channel")
+	dstChannel, _ := sendResult.eventAttribute("send_packet", "packet_dst_channel")
+	sequence, _ := sendResult.eventAttribute("send_packet", "packet_sequence")
+	t.Logf("ICA packet sent: port=%s channel=%s sequence=%s", srcPort, srcChannel, sequence)
+
+	// ── Wait for the relayer to deliver the ICA packet to the host ──
+	err = testutil.WaitForBlocks(ctx, 10, controller, host)
 	require.NoError(t, err)
 
-	queryTxCmd := []string{
-		osmosis.Config().Bin, "q", "tx", broadcastResp.TxHash,
-		"--node", osmosis.GetRPCAddress(),
-		"--output", "json",
-	}
-	txResult, _, err := osmosis.Exec(ctx, queryTxCmd, nil)
-	if err != nil {
-		t.Logf("WARNING: could not query tx result: %v", err)
-	} else {
-		var txResp struct {
-			Code   int    `json:"code"`
-			RawLog string `json:"raw_log"`
-		}
-		if err := json.Unmarshal(txResult, &txResp); err == nil {
-			t.Logf("ICA SendTx execution: code=%d raw_log=%s", txResp.Code, txResp.RawLog)
-			require.Equal(t, 0, txResp.Code, "ICA SendTx execution failed: %s", txResp.RawLog)
-		}
+	// Explicitly flush any remaining packets on the ICA channel to ensure
+	// delivery. The channel is taken from the send_packet event (not
+	// hardcoded) since channel IDs depend on creation order.
+	flushPath(t, ctx, env, controller, srcChannel, dstChannel)
+	err = testutil.WaitForBlocks(ctx, 5, controller, host)
+	require.NoError(t, err)
+
+	return srcPort, sequence
+}
+
+// flushPath relays pending packets on env.path over the channel whose ends
+// are srcChannel on chain and dstChannel on the other chain. The relayer
+// names channels by their end on the path's first chain, which is always the
+// one linked to Lumera, never Lumera itself.
+func flushPath(t *testing.T, ctx context.Context, env *icaTestEnv, chain *cosmos.CosmosChain, srcChannel, dstChannel string) {
+	t.Helper()
+	channelID := srcChannel
+	if chain == env.lumera {
+		channelID = dstChannel
 	}
+	require.NoError(t, env.relayer.Flush(ctx, env.eRep, env.path, channelID))
+}
 
-	// ── Wait for the relayer to deliver the ICA packet to Lumera ──
-	err = testutil.WaitForBlocks(ctx, 10, osmosis, lumera)
-	require.NoError(t, err)
+// icaAck is the host-side outcome of an ICA packet.
+type icaAck struct {
+	// Ack is the raw acknowledgement written on Lumera, e.g. {"result":"..."}
+	// or {"error":"ABCI code: 5: error handling packet: see events for details"}.
+	Ack string
+	// Error is the full (non-deterministic) error from the ics27_packet event,
+	// empty on success.
+	Error string
+}
 
-	// Explicitly flush any remaining packets on the ICA channel to ensure
-	// delivery. The channel is found dynamically by its "icacontroller-" port
-	// prefix (not hardcoded) since channel IDs depend on creation order.
-	icaChanID := findICAChannel(t, ctx, r, eRep, osmosis.Config().ChainID)
-	require.NoError(t, r.Flush(ctx, eRep, ibcPath, icaChanID))
-	err = testutil.WaitForBlocks(ctx, 5, osmosis, lumera)
-	require.NoError(t, err)
+// Success reports whether the host executed the packet without error.
+func (a icaAck) Success() bool {
+	return !strings.Contains(a.Ack, `"error"`)
+}
 
-	// ── Verify action was created on Lumera ──
-	verifyActionCreated(t, ctx, lumera, icaAddr)
+// queryICAAck finds the acknowledgement the host chain (normally Lumera) wrote
+// for the ICA packet identified by its controller-side source port and
+// sequence.
+func queryICAAck(t *testing.T, ctx context.Context, host *cosmos.CosmosChain, srcPort, sequence string) icaAck {
+	t.Helper()
+	res := queryAckTx(t, ctx, host, srcPort, "", sequence)
+
+	var ack icaAck
+	ack.Ack, _ = res.eventAttribute("write_acknowledgement", "packet_ack")
+	ack.Error, _ = res.eventAttribute("ics27_packet", "error")
+	t.Logf("ICA ack: %s (error: %q)", ack.Ack, ack.Error)
+	return ack
+}
+
+// queryAckTx waits for the tx in which chain wrote the acknowledgement of the
+// packet with the given source port, channel ("" for any) and sequence.
+func queryAckTx(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, srcPort, srcChannel, sequence string) txResult {
+	t.Helper()
+	query := fmt.Sprintf("write_acknowledgement.packet_src_port='%s' AND write_acknowledgement.packet_sequence='%s'", srcPort, sequence)
+	if srcChannel != "" {
+		query += fmt.Sprintf(" AND write_acknowledgement.packet_src_channel='%s'", srcChannel)
+	}
+
+	var res txResult
+	require.Eventually(t, func() bool {
+		var resp struct {
+			Txs []txResult `json:"txs"`
+		}
+		if err := queryJSON(ctx, chain, &resp, "txs", "--query", query); err != nil || len(resp.Txs) == 0 {
+			return false
+		}
+		res = resp.Txs[0]
+		return true
+	}, time.Minute, 3*time.Second, "no acknowledgement found for %s/%s", srcPort, sequence)
+	return res
 }
 
 // verifyActionCreated queries the action module on Lumera and asserts that an
 // action with the expected creator (the ICA address) and type CASCADE exists.
 // This confirms the full ICS-27 round-trip: controller tx → relay → host execution.
 func verifyActionCreated(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, creator string) {
-	queryCmd := []string{
-		lumera.Config().Bin, "q", "action", "list-actions",
-		"--node", lumera.GetRPCAddress(),
-		"--output", "json",
-	}
-	stdout, _, err := lumera.Exec(ctx, queryCmd, nil)
-	require.NoError(t, err)
-	t.Logf("list-actions response: %s", string(stdout))
+	actions := listActions(t, ctx, lumera)
 	t.Logf("Expected creator: %s", creator)
-
-	var resp struct {
-		Actions []struct {
-			Creator    string `json:"creator"`
-			ActionID   string `json:"actionID"`
-			ActionType string `json:"actionType"`
-			State      string `json:"state"`
-		} `json:"actions"`
-	}
-	require.NoError(t, json.Unmarshal(stdout, &resp))
-	t.Logf("Found %d actions total", len(resp.Actions))
+	t.Logf("Found %d actions total", len(actions))
 
 	// Find the action created by our ICA
 	found := false
-	for _, a := range resp.Actions {
+	for _, a := range actions {
 		t.Logf("  Action: ID=%s Creator=%s Type=%s State=%s", a.ActionID, a.Creator, a.ActionType, a.State)
 		if a.Creator == creator {
 			found = true
@@ -0,0 +1,109 @@
+// ica_transfer_test.go — ICS-20 transfer initiated by an interchain account.
+// The controller user packs a MsgTransfer into an ICA packet, and Lumera
+// executes it as the ICA, sending LUME back to the user's controller address.
+// The inner ICS-20 packet (Lumera → controller) is sent while Lumera handles
+// the outer ICS-27 packet (controller → Lumera).
+package interchaintest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// icaTransferAmount is the LUME (in ulume) the ICA sends home.
+	icaTransferAmount = 1_000_000_000
+	// icaTransferTimeout bounds the inner packet. It is counted from when the
+	// ICA packet is built, so it must cover relaying the outer packet too.
+	icaTransferTimeout = 10 * time.Minute
+)
+
+// TestICAInitiatedTransfer has the ICA send LUME to its owner's controller
+// address via MsgTransfer.
+func TestICAInitiatedTransfer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICA transfer e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+
+	t.Logf("Testing ICA-initiated transfer on Lumera %s (local image: %v)", version, useLocal)
+
+	env := newICATestEnv(t, ctx, GetLumeraChainConfig(version, useLocal))
+	registerICA(t, ctx, env)
+	fundICA(t, ctx, env.lumera, env.icaAddr)
+
+	lumera, controller := env.lumera, env.controller
+	denom := lumera.Config().Denom
+	owner := env.user.FormattedAddress()
+	amount := math.NewInt(icaTransferAmount)
+
+	controllerCh := channelByPort(t, ctx, env, controller, transferPort)
+	lumeraCh := controllerCh.Counterparty.ChannelID
+	voucher := ibcDenom(controllerCh.PortID, controllerCh.ChannelID, denom)
+	escrow := escrowAddress(t, lumera, ibc.ChannelOutput{PortID: transferPort, ChannelID: lumeraCh})
+
+	vouchersBefore, err := controller.GetBalance(ctx, owner, voucher)
+	require.NoError(t, err)
+	icaBefore, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	escrowBefore, err := lumera.GetBalance(ctx, escrow, denom)
+	require.NoError(t, err)
+
+	msg := msgTransfer(env.icaAddr, owner, lumeraCh, denom, amount, time.Now().Add(icaTransferTimeout))
+	port, seq := sendICAPacket(t, ctx, env, generateICAPacket(t, ctx, lumera, msg))
+
+	t.Run("OuterAckSucceeds", func(t *testing.T) {
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.True(t, ack.Success(), "ICA MsgTransfer failed: %s", ack.Error)
+	})
+
+	t.Run("InnerPacketRelayed", func(t *testing.T) {
+		// The inner send_packet is emitted by the tx that executed the ICA
+		// packet on Lumera.
+		res := queryAckTx(t, ctx, lumera, port, "", seq)
+		innerPort, ok := res.eventAttribute("send_packet", "packet_src_port")
+		require.True(t, ok, "executing the ICA packet should send an ICS-20 packet")
+		innerChannel, _ := res.eventAttribute("send_packet", "packet_src_channel")
+		innerSeq, _ := res.eventAttribute("send_packet", "packet_sequence")
+		require.Equal(t, transferPort, innerPort)
+		require.Equal(t, lumeraCh, innerChannel)
+		t.Logf("Inner transfer packet: %s/%s sequence %s", innerPort, innerChannel, innerSeq)
+
+		flushPath(t, ctx, env, lumera, innerChannel, controllerCh.ChannelID)
+		waitForBalance(t, ctx, controller, owner, voucher, vouchersBefore.Add(amount))
+		requireDenomTrace(t, ctx, controller, voucher, transferPort+"/"+controllerCh.ChannelID, denom)
+	})
+
+	t.Run("LumeEscrowed", func(t *testing.T) {
+		// Whatever the ICA sent is now held in the transfer escrow on Lumera.
+		ica, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+		require.Equal(t, icaBefore.Sub(amount).String(), ica.String())
+		escrowed, err := lumera.GetBalance(ctx, escrow, denom)
+		require.NoError(t, err)
+		require.Equal(t, escrowBefore.Add(amount).String(), escrowed.String())
+	})
+}
+
+// msgTransfer sends amount of denom from sender on Lumera to receiver over
+// channel, timing out at timeout.
+func msgTransfer(sender, receiver, channel, denom string, amount math.Int, timeout time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/ibc.applications.transfer.v1.MsgTransfer",
+		"sourcePort":       transferPort,
+		"sourceChannel":    channel,
+		"token":            map[string]string{"denom": denom, "amount": amount.String()},
+		"sender":           sender,
+		"receiver":         receiver,
+		"timeoutTimestamp": fmt.Sprint(timeout.UnixNano()),
+	}
+}
@@ -0,0 +1,108 @@
+// lumera_releases_test.go - Offline tests of the version-keyed release
+// registry.
+package interchaintest_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/semver"
+)
+
+func TestLumeraReleaseFor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"v1.9.9", "< v1.10.0"},
+		{"v1.9.99", "< v1.10.0"},
+		{"v1.10.0", ">= v1.10.0"},
+		{"v1.10.0-rc1", ">= v1.10.0"},
+		{"v1.10.1", ">= v1.10.0"},
+		{"1.10.1", ">= v1.10.0"},
+		{"v1.10", ">= v1.10.0"},
+		{"v1.9", "< v1.10.0"},
+		{"v2.0.0", ">= v1.10.0"},
+		{"v0.1.0", "< v1.10.0"},
+		{"v1.10.1+build.5", ">= v1.10.0"},
+		{"local", ">= v1.10.0"},
+		{"main", ">= v1.10.0"},
+		{"", ">= v1.10.0"},
+		{DefaultLumeraVersion, ">= v1.10.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			require.Equal(t, tt.want, lumeraReleaseFor(tt.version).name)
+		})
+	}
+}
+
+func TestLumeraReleaseFeeMiddleware(t *testing.T) {
+	require.True(t, lumeraReleaseFor("v1.9.0").feeMiddleware)
+	require.False(t, lumeraReleaseFor("v1.10.0").feeMiddleware, "ibc-go v10 has no ICS-29")
+	require.False(t, lumeraReleaseFor(DefaultLumeraVersion).feeMiddleware)
+}
+
+// TestLumeraReleasesContiguous checks that the ranges are ordered, don't
+// overlap and leave no gaps, so every semver maps to exactly one entry.
+func TestLumeraReleasesContiguous(t *testing.T) {
+	require.NotEmpty(t, lumeraReleases)
+	require.Empty(t, lumeraReleases[0].min, "first range must be unbounded below")
+	require.Empty(t, lumeraReleases[len(lumeraReleases)-1].max, "last range must be unbounded above")
+	for i, r := range lumeraReleases {
+		if r.min != "" && r.max != "" {
+			require.Negative(t, semver.Compare(r.min, r.max), "%s: empty range", r.name)
+		}
+		if i > 0 {
+			require.Equal(t, lumeraReleases[i-1].max, r.min, "%s must start where %s ends", r.name, lumeraReleases[i-1].name)
+		}
+	}
+}
+
+func TestLumeraReleaseGenesis(t *testing.T) {
+	genesis := readTestGenesis(t)
+
+	out, err := modifyLumeraGenesis(LumeraConfig, "v1.9.0", genesis, nil)
+	require.NoError(t, err)
+	g := decodeGenesis(t, out)
+	appState := g["app_state"].(map[string]interface{})
+	require.Contains(t, appState, "crisis", "x/crisis is still wired before v1.10.0")
+	require.Nil(t, appState["consensus"], "consensus params stay in the top-level section before v1.10.0")
+	require.NotContains(t, appState, "nft")
+
+	out, err = modifyLumeraGenesis(LumeraConfig, "v1.10.0", genesis, nil)
+	require.NoError(t, err)
+	g = decodeGenesis(t, out)
+	appState = g["app_state"].(map[string]interface{})
+	require.NotContains(t, appState, "crisis")
+	require.Equal(t, "22020096", genesisValue(t, g, "app_state.consensus.params.block.max_bytes"))
+}
+
+func TestLumeraReleaseStartArgs(t *testing.T) {
+	config := GetLumeraChainConfig("v1.9.0", false)
+	require.Equal(t, []string{lumeraStripArgsEnv + "="}, config.Env)
+
+	config = GetLumeraChainConfig("v1.10.0", false)
+	require.Equal(t, []string{lumeraStripArgsEnv + "=" + crisisSkipAssertInvariants}, config.Env)
+	require.Equal(t, []string{"--claims-path", "/var/cosmos-chain/lumera/config/claims.csv"}, config.AdditionalStartArgs)
+
+	// A local image is tagged "local" but still follows LUMERA_VERSION.
+	config = GetLumeraChainConfig("v1.9.0", true)
+	require.Equal(t, "local", config.Images[0].Version)
+	require.Equal(t, []string{lumeraStripArgsEnv + "="}, config.Env)
+
+	out, err := config.ModifyGenesis(config, readTestGenesis(t))
+	require.NoError(t, err)
+	require.Contains(t, decodeGenesis(t, out)["app_state"], "crisis")
+
+	// Upgrading across v1.10.0: genesis follows the starting release, the
+	// wrapper already strips what the target rejects.
+	config = GetLumeraChainConfig("v1.9.0", false, WithUpgradeTo("v1.10.1"))
+	require.Equal(t, []string{lumeraStripArgsEnv + "=" + crisisSkipAssertInvariants}, config.Env)
+	out, err = config.ModifyGenesis(config, readTestGenesis(t))
+	require.NoError(t, err)
+	require.Contains(t, decodeGenesis(t, out)["app_state"], "crisis")
+
+	config = GetLumeraChainConfig("v1.10.0", false, WithUpgradeTo("v1.10.1"))
+	require.Equal(t, []string{lumeraStripArgsEnv + "=" + crisisSkipAssertInvariants}, config.Env)
+}
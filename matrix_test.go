@@ -0,0 +1,175 @@
+// matrix_test.go — Version matrix for the e2e suites. LUMERA_VERSIONS and
+// the controller versions (ICA_CONTROLLER_VERSIONS, or OSMOSIS_VERSIONS for
+// Osmosis; all comma-separated) expand a test into one subtest per
+// combination, each on its own Docker network, and a compatibility table of
+// the results is logged once all of them have run.
+package interchaintest_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// compatPair is one cell of the version matrix. Controller is empty for
+// Lumera-only tests.
+type compatPair struct {
+	Lumera                        string
+	Controller, ControllerVersion string
+}
+
+// name is the subtest name of the pair.
+func (p compatPair) name() string {
+	if p.Controller == "" {
+		return "lumera=" + p.Lumera
+	}
+	return "lumera=" + p.Lumera + "," + p.Controller + "=" + p.ControllerVersion
+}
+
+const (
+	compatPass = "PASS"
+	compatFail = "FAIL"
+	compatSkip = "SKIP"
+)
+
+// compatResult is the outcome of one pair.
+type compatResult struct {
+	compatPair
+	Result string
+}
+
+// compatMatrix collects per-pair results of a test and reports them as a
+// table when the test finishes.
+type compatMatrix struct {
+	title   string
+	mu      sync.Mutex
+	results []compatResult
+}
+
+// newCompatMatrix returns a matrix that logs its table when t completes, and
+// appends it to $GITHUB_STEP_SUMMARY on GitHub Actions.
+func newCompatMatrix(t *testing.T, title string) *compatMatrix {
+	m := &compatMatrix{title: title}
+	t.Cleanup(func() {
+		table := m.table()
+		t.Logf("Compatibility summary:\n%s", table)
+		if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+			if err := appendFile(path, "### "+title+"\n\n"+table+"\n"); err != nil {
+				t.Logf("write step summary: %v", err)
+			}
+		}
+	})
+	return m
+}
+
+// run runs fn as a subtest for pair and records its outcome.
+func (m *compatMatrix) run(t *testing.T, pair compatPair, fn func(t *testing.T)) {
+	t.Run(pair.name(), func(t *testing.T) {
+		t.Cleanup(func() {
+			result := compatPass
+			switch {
+			case t.Failed():
+				result = compatFail
+			case t.Skipped():
+				result = compatSkip
+			}
+			m.record(compatResult{compatPair: pair, Result: result})
+		})
+		fn(t)
+	})
+}
+
+func (m *compatMatrix) record(r compatResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, r)
+}
+
+// table renders the results as a Markdown table, with a controller column
+// only if some pair has one.
+func (m *compatMatrix) table() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	withController := false
+	for _, r := range m.results {
+		withController = withController || r.Controller != ""
+	}
+	var b strings.Builder
+	if withController {
+		b.WriteString("| Lumera | Controller | Result |\n| ------ | ---------- | ------ |\n")
+	} else {
+		b.WriteString("| Lumera | Result |\n| ------ | ------ |\n")
+	}
+	for _, r := range m.results {
+		if withController {
+			fmt.Fprintf(&b, "| %s | %s %s | %s |\n", r.Lumera, r.Controller, r.ControllerVersion, r.Result)
+		} else {
+			fmt.Fprintf(&b, "| %s | %s |\n", r.Lumera, r.Result)
+		}
+	}
+	return b.String()
+}
+
+// lumeraVersionsFromEnv returns the Lumera versions to test: LUMERA_VERSIONS
+// if set, else the single version of lumeraVersionFromEnv. A local image has
+// a single version, so it can't be combined with several.
+func lumeraVersionsFromEnv(t *testing.T) ([]string, bool) {
+	t.Helper()
+	version, useLocal := lumeraVersionFromEnv()
+	versions := splitVersions(os.Getenv("LUMERA_VERSIONS"))
+	if len(versions) == 0 {
+		return []string{version}, useLocal
+	}
+	if useLocal && len(versions) > 1 {
+		t.Fatalf("USE_LOCAL_IMAGE=true runs a single image; LUMERA_VERSIONS=%s", os.Getenv("LUMERA_VERSIONS"))
+	}
+	return versions, useLocal
+}
+
+// appendFile appends s to the file at path, creating it if needed.
+func appendFile(path, s string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func TestCompatMatrix(t *testing.T) {
+	m := &compatMatrix{title: "test"}
+	m.run(t, compatPair{Lumera: "v1.10.0", Controller: "osmosis", ControllerVersion: "v25.0.0"}, func(t *testing.T) {})
+	m.run(t, compatPair{Lumera: "v1.10.1", Controller: "osmosis", ControllerVersion: "v25.0.0"}, func(t *testing.T) {
+		t.Run("Nested", func(t *testing.T) { t.Skip("not applicable") })
+	})
+	m.run(t, compatPair{Lumera: "v1.10.1", Controller: "gaia", ControllerVersion: "v18.1.0"}, func(t *testing.T) { t.Skip("image missing") })
+	m.record(compatResult{compatPair: compatPair{Lumera: "v1.9.0", Controller: "osmosis", ControllerVersion: "v25.0.0"}, Result: compatFail})
+
+	require.Equal(t, `| Lumera | Controller | Result |
+| ------ | ---------- | ------ |
+| v1.10.0 | osmosis v25.0.0 | PASS |
+| v1.10.1 | osmosis v25.0.0 | PASS |
+| v1.10.1 | gaia v18.1.0 | SKIP |
+| v1.9.0 | osmosis v25.0.0 | FAIL |
+`, m.table())
+
+	lumeraOnly := &compatMatrix{}
+	lumeraOnly.run(t, compatPair{Lumera: "v1.10.1"}, func(t *testing.T) {
+		require.Equal(t, "TestCompatMatrix/lumera=v1.10.1", t.Name())
+	})
+	require.Equal(t, "| Lumera | Result |\n| ------ | ------ |\n| v1.10.1 | PASS |\n", lumeraOnly.table())
+}
+
+func TestSplitVersions(t *testing.T) {
+	require.Nil(t, splitVersions(""))
+	require.Nil(t, splitVersions(" , "))
+	require.Equal(t, []string{"v1.10.0", "v1.10.1"}, splitVersions("v1.10.0, v1.10.1,"))
+}
@@ -0,0 +1,334 @@
+// supernode_test.go — Supernode registration and lifecycle scenarios on a
+// single Lumera chain: register a supernode from the validator, query its
+// state, report metrics, stop/start it and finally deregister it.
+package interchaintest_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/strangelove-ventures/interchaintest/v8/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// supernodeTestMinStake replaces the 10,000 LUME genesis default for
+	// minimum_stake_for_sn so test validators qualify without extra bonding.
+	supernodeTestMinStake = 1_000_000 // 1 LUME
+
+	// supernodeTestReportingThreshold replaces the genesis reporting_threshold
+	// so a supernode that stops reporting is demoted within a few blocks.
+	supernodeTestReportingThreshold = 5
+
+	supernodeTestIP      = "192.168.1.10"
+	supernodeTestP2PPort = "4445"
+
+	supernodeStateActive    = "SUPERNODE_STATE_ACTIVE"
+	supernodeStateStopped   = "SUPERNODE_STATE_STOPPED"
+	supernodeStateDisabled  = "SUPERNODE_STATE_DISABLED"
+	supernodeStatePostponed = "SUPERNODE_STATE_POSTPONED"
+)
+
+// TestLumeraSupernodeLifecycle registers a supernode from the genesis
+// validator and walks it through the stop/start/deregister lifecycle.
+func TestLumeraSupernodeLifecycle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping supernode e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	config := GetLumeraChainConfig(version, useLocal,
+		WithSupernodeMinimumStake(math.NewInt(supernodeTestMinStake)),
+		WithGenesisKV("app_state.supernode.params.reporting_threshold", strconv.Itoa(supernodeTestReportingThreshold)),
+	)
+
+	t.Logf("Testing supernodes on Lumera %s (local image: %v)", version, useLocal)
+
+	lumera := startLumera(t, ctx, config)
+
+	// The genesis validator key is named "validator" in the node keyring.
+	valoper, err := lumera.GetNode().KeyBech32(ctx, "validator", "val")
+	require.NoError(t, err)
+
+	snAccount := interchaintest.GetAndFundTestUsers(t, ctx, "supernode", math.NewInt(10_000_000_000), lumera)[0]
+
+	t.Run("Params", func(t *testing.T) {
+		params := querySupernodeParams(t, ctx, lumera)
+		require.Equal(t, strconv.Itoa(supernodeTestMinStake), params.MinimumStakeForSn.Amount,
+			"minimum_stake_for_sn should be lowered by WithSupernodeMinimumStake")
+		require.Equal(t, strconv.Itoa(supernodeTestReportingThreshold), params.ReportingThreshold)
+	})
+
+	t.Run("RegisterRequiresValidatorOperator", func(t *testing.T) {
+		res := broadcastMsgs(t, ctx, lumera, snAccount.KeyName(),
+			msgRegisterSupernode(snAccount.FormattedAddress(), valoper, supernodeTestIP, snAccount.FormattedAddress(), supernodeTestP2PPort))
+		require.NotEqual(t, 0, res.Code, "only the validator operator may register its supernode")
+		t.Logf("Non-operator registration rejected: %s", res.RawLog)
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		validatorAcc := validatorAccountAddress(t, ctx, lumera)
+		res := broadcastMsgs(t, ctx, lumera, "validator",
+			msgRegisterSupernode(validatorAcc, valoper, supernodeTestIP, snAccount.FormattedAddress(), supernodeTestP2PPort))
+		requireTxSuccess(t, res)
+
+		sn := querySupernode(t, ctx, lumera, valoper)
+		require.Equal(t, valoper, sn.ValidatorAddress)
+		require.Equal(t, snAccount.FormattedAddress(), sn.SupernodeAccount)
+		require.Equal(t, supernodeTestP2PPort, sn.P2PPort)
+		require.Equal(t, supernodeStateActive, sn.currentState())
+	})
+
+	t.Run("ReportMetrics", func(t *testing.T) {
+		testReportSupernodeMetrics(t, ctx, lumera, valoper, snAccount)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		validatorAcc := validatorAccountAddress(t, ctx, lumera)
+		res := broadcastMsgs(t, ctx, lumera, "validator",
+			msgStopSupernode(validatorAcc, valoper, "maintenance"))
+		requireTxSuccess(t, res)
+		require.Equal(t, supernodeStateStopped, querySupernode(t, ctx, lumera, valoper).currentState())
+	})
+
+	t.Run("Start", func(t *testing.T) {
+		validatorAcc := validatorAccountAddress(t, ctx, lumera)
+		res := broadcastMsgs(t, ctx, lumera, "validator",
+			msgStartSupernode(validatorAcc, valoper))
+		requireTxSuccess(t, res)
+		require.Equal(t, supernodeStateActive, querySupernode(t, ctx, lumera, valoper).currentState())
+	})
+
+	t.Run("Deregister", func(t *testing.T) {
+		validatorAcc := validatorAccountAddress(t, ctx, lumera)
+		res := broadcastMsgs(t, ctx, lumera, "validator",
+			msgDeregisterSupernode(validatorAcc, valoper))
+		requireTxSuccess(t, res)
+		require.Equal(t, supernodeStateDisabled, querySupernode(t, ctx, lumera, valoper).currentState())
+	})
+}
+
+// testReportSupernodeMetrics submits a metrics report from the supernode
+// account, checks that reports from unrelated accounts are rejected, that a
+// supernode reporting within reporting_threshold blocks stays active, and that
+// one which misses the threshold is postponed until it reports again.
+func testReportSupernodeMetrics(
+	t *testing.T, ctx context.Context,
+	lumera *cosmos.CosmosChain,
+	valoper string, snAccount ibc.Wallet,
+) {
+	params := querySupernodeParams(t, ctx, lumera)
+	threshold, err := strconv.Atoi(params.ReportingThreshold)
+	require.NoError(t, err, "reporting_threshold should be an integer: %q", params.ReportingThreshold)
+	t.Logf("reporting_threshold: %d", threshold)
+
+	res := broadcastMsgs(t, ctx, lumera, snAccount.KeyName(),
+		msgReportSupernodeMetrics(valoper, snAccount.FormattedAddress()))
+	requireTxSuccess(t, res)
+
+	outsider := interchaintest.GetAndFundTestUsers(t, ctx, "outsider", math.NewInt(1_000_000_000), lumera)[0]
+	res = broadcastMsgs(t, ctx, lumera, outsider.KeyName(),
+		msgReportSupernodeMetrics(valoper, outsider.FormattedAddress()))
+	require.NotEqual(t, 0, res.Code, "metrics from a non-supernode account should be rejected")
+	t.Logf("Outsider metrics report rejected: %s", res.RawLog)
+
+	// Keep reporting inside the threshold window; the supernode must not be
+	// demoted while its reports are fresh.
+	for i := 0; i < 2; i++ {
+		require.NoError(t, testutil.WaitForBlocks(ctx, threshold/2+1, lumera))
+		res = broadcastMsgs(t, ctx, lumera, snAccount.KeyName(),
+			msgReportSupernodeMetrics(valoper, snAccount.FormattedAddress()))
+		requireTxSuccess(t, res)
+	}
+	require.Equal(t, supernodeStateActive, querySupernode(t, ctx, lumera, valoper).currentState(),
+		"supernode reporting within reporting_threshold should stay active")
+
+	// Stop reporting and wait the threshold out: the supernode must be
+	// postponed once its last report is older than reporting_threshold.
+	lastReport := res.Height
+	require.NoError(t, testutil.WaitForBlocks(ctx, threshold+1, lumera))
+	require.Eventually(t, func() bool {
+		return querySupernode(t, ctx, lumera, valoper).currentState() == supernodeStatePostponed
+	}, time.Minute, time.Second,
+		"supernode silent since height %s should be postponed after %d blocks", lastReport, threshold)
+
+	// A fresh report brings it back so the stop/start lifecycle can continue.
+	res = broadcastMsgs(t, ctx, lumera, snAccount.KeyName(),
+		msgReportSupernodeMetrics(valoper, snAccount.FormattedAddress()))
+	requireTxSuccess(t, res)
+	require.Eventually(t, func() bool {
+		return querySupernode(t, ctx, lumera, valoper).currentState() == supernodeStateActive
+	}, time.Minute, time.Second, "postponed supernode should recover after reporting metrics")
+}
+
+// registerTestSupernode registers a supernode for the genesis validator with
+// a freshly funded supernode account, and returns that account.
+func registerTestSupernode(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) ibc.Wallet {
+	t.Helper()
+	valoper, err := lumera.GetNode().KeyBech32(ctx, "validator", "val")
+	require.NoError(t, err)
+
+	snAccount := interchaintest.GetAndFundTestUsers(t, ctx, "supernode", math.NewInt(10_000_000_000), lumera)[0]
+	res := broadcastMsgs(t, ctx, lumera, "validator",
+		msgRegisterSupernode(validatorAccountAddress(t, ctx, lumera), valoper, supernodeTestIP, snAccount.FormattedAddress(), supernodeTestP2PPort))
+	requireTxSuccess(t, res)
+	require.Equal(t, supernodeStateActive, querySupernode(t, ctx, lumera, valoper).currentState())
+	t.Logf("Registered supernode %s (account %s)", valoper, snAccount.FormattedAddress())
+	return snAccount
+}
+
+// validatorAccountAddress returns the account address of the genesis validator.
+func validatorAccountAddress(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) string {
+	t.Helper()
+	addr, err := lumera.GetNode().AccountKeyBech32(ctx, "validator")
+	require.NoError(t, err)
+	return addr
+}
+
+// supernodeInfo is the subset of the SuperNode query response used by tests.
+type supernodeInfo struct {
+	ValidatorAddress string `json:"validator_address"`
+	SupernodeAccount string `json:"supernode_account"`
+	P2PPort          string `json:"p2p_port"`
+	Note             string `json:"note"`
+	States           []struct {
+		State  string `json:"state"`
+		Height string `json:"height"`
+	} `json:"states"`
+	PrevIPAddresses []struct {
+		Address string `json:"address"`
+		Height  string `json:"height"`
+	} `json:"prev_ip_addresses"`
+}
+
+// currentState returns the most recent supernode state.
+func (sn supernodeInfo) currentState() string {
+	if len(sn.States) == 0 {
+		return ""
+	}
+	return sn.States[len(sn.States)-1].State
+}
+
+// currentIPAddress returns the most recently registered supernode IP address.
+func (sn supernodeInfo) currentIPAddress() string {
+	if len(sn.PrevIPAddresses) == 0 {
+		return ""
+	}
+	return sn.PrevIPAddresses[len(sn.PrevIPAddresses)-1].Address
+}
+
+// supernodeParams is the subset of supernode module params used by tests.
+type supernodeParams struct {
+	MinimumStakeForSn struct {
+		Denom  string `json:"denom"`
+		Amount string `json:"amount"`
+	} `json:"minimum_stake_for_sn"`
+	ReportingThreshold string `json:"reporting_threshold"`
+}
+
+func querySupernodeParams(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain) supernodeParams {
+	t.Helper()
+	var resp struct {
+		Params supernodeParams `json:"params"`
+	}
+	require.NoError(t, queryJSON(ctx, lumera, &resp, "supernode", "params"))
+	return resp.Params
+}
+
+func querySupernode(t *testing.T, ctx context.Context, lumera *cosmos.CosmosChain, valoper string) supernodeInfo {
+	t.Helper()
+	sn, err := tryQuerySupernode(ctx, lumera, valoper)
+	require.NoError(t, err)
+	return sn
+}
+
+// tryQuerySupernode returns the supernode registered for valoper, or an error
+// if none exists.
+func tryQuerySupernode(ctx context.Context, lumera *cosmos.CosmosChain, valoper string) (supernodeInfo, error) {
+	var resp struct {
+		Supernode supernodeInfo `json:"supernode"`
+	}
+	if err := queryJSON(ctx, lumera, &resp, "supernode", "get-super-node", valoper); err != nil {
+		return supernodeInfo{}, err
+	}
+	return resp.Supernode, nil
+}
+
+func msgRegisterSupernode(creator, valoper, ip, snAccount, p2pPort string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/lumera.supernode.v1.MsgRegisterSupernode",
+		"creator":          creator,
+		"validatorAddress": valoper,
+		"ipAddress":        ip,
+		"supernodeAccount": snAccount,
+		"p2pPort":          p2pPort,
+	}
+}
+
+func msgUpdateSupernode(creator, valoper, ip, note, snAccount, p2pPort string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/lumera.supernode.v1.MsgUpdateSupernode",
+		"creator":          creator,
+		"validatorAddress": valoper,
+		"ipAddress":        ip,
+		"note":             note,
+		"supernodeAccount": snAccount,
+		"p2pPort":          p2pPort,
+	}
+}
+
+func msgStopSupernode(creator, valoper, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/lumera.supernode.v1.MsgStopSupernode",
+		"creator":          creator,
+		"validatorAddress": valoper,
+		"reason":           reason,
+	}
+}
+
+func msgStartSupernode(creator, valoper string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/lumera.supernode.v1.MsgStartSupernode",
+		"creator":          creator,
+		"validatorAddress": valoper,
+	}
+}
+
+func msgDeregisterSupernode(creator, valoper string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/lumera.supernode.v1.MsgDeregisterSupernode",
+		"creator":          creator,
+		"validatorAddress": valoper,
+	}
+}
+
+func msgReportSupernodeMetrics(valoper, snAccount string) map[string]interface{} {
+	return map[string]interface{}{
+		"@type":            "/lumera.supernode.v1.MsgReportSupernodeMetrics",
+		"validatorAddress": valoper,
+		"supernodeAccount": snAccount,
+		"metrics": map[string]interface{}{
+			"versionMajor":     2,
+			"versionMinor":     4,
+			"versionPatch":     0,
+			"cpuCoresTotal":    8,
+			"cpuUsagePercent":  10,
+			"memTotalGb":       32,
+			"memUsagePercent":  25,
+			"memFreeGb":        24,
+			"diskTotalGb":      1000,
+			"diskUsagePercent": 10,
+			"diskFreeGb":       900,
+			"uptimeSeconds":    3600,
+			"peersCount":       1,
+		},
+	}
+}
@@ -1,4 +1,4 @@
-// buildpacket builds an ICA packet containing a real MsgRequestAction
+// buildpacket builds an ICA packet containing real MsgRequestActions
 // using the Lumera SDK's cascade client. It lives in a separate Go module
 // to avoid the ibc-go/v8 vs v10 init() conflict with interchaintest.
 //
@@ -7,49 +7,122 @@
 //	go run . --mnemonic "..." --ica-address lumera1... --grpc-addr localhost:9090 \
 //	         --chain-id lumera-testnet-2 --file /tmp/test.bin --owner-hrp osmo
 //
-// Outputs the ICA packet JSON to stdout (errors go to stderr).
+// Outputs the ICA packet JSON to stdout (errors go to stderr). --expiration
+// overrides the SDK's expiration (chain expiration_duration + 1h) with a
+// fixed offset from now, so tests can let actions lapse quickly.
+//
+// --file accepts a comma-separated list to batch one MsgRequestAction per
+// file into the same packet. With --output msgs and no --ica-address the
+// messages are instead printed as a JSON array for a regular tx signed by
+// the mnemonic's own Lumera account.
+//
+// With --mode finalize-metadata it instead prints the MsgFinalizeAction
+// metadata a supernode would submit for a pending cascade action:
+//
+//	go run . --mode finalize-metadata --grpc-addr localhost:9090 --action-id 1
+//
+// With --mode claim it prints an ICA packet with a MsgClaim that claims a
+// Pastel balance into the ICA. The caller supplies the Pastel public key and
+// the signature over "<old-address>.<pub-key>.<ica-address>":
+//
+//	go run . --mode claim --ica-address lumera1... --old-address Pt... \
+//	         --pub-key 02ab... --signature 3045...
 package main
 
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	actiontypes "github.com/LumeraProtocol/lumera/x/action/v1/types"
+	claimtypes "github.com/LumeraProtocol/lumera/x/claim/types"
 	"github.com/LumeraProtocol/sdk-go/cascade"
 	"github.com/LumeraProtocol/sdk-go/ica"
 	sdkcrypto "github.com/LumeraProtocol/sdk-go/pkg/crypto"
+	"github.com/LumeraProtocol/supernode/v2/pkg/cascadekit"
 
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/gogoproto/jsonpb"
 	gogoproto "github.com/cosmos/gogoproto/proto"
 	icatypes "github.com/cosmos/ibc-go/v10/modules/apps/27-interchain-accounts/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
+	mode := flag.String("mode", "request", "What to build: request (MsgRequestAction), finalize-metadata or claim (ICA packet with MsgClaim)")
 	mnemonic := flag.String("mnemonic", "", "BIP39 mnemonic for key derivation")
-	icaAddress := flag.String("ica-address", "", "ICA address on Lumera (host chain)")
+	icaAddress := flag.String("ica-address", "", "ICA address on Lumera (host chain); empty creates actions for the mnemonic's own address")
 	grpcAddr := flag.String("grpc-addr", "", "Lumera gRPC address (host:port)")
 	chainID := flag.String("chain-id", "", "Lumera chain ID")
-	filePath := flag.String("file", "", "Path to the file to create action for")
+	filePaths := flag.String("file", "", "Comma-separated files to create actions for (one MsgRequestAction each)")
 	ownerHRP := flag.String("owner-hrp", "osmo", "Bech32 HRP for controller chain")
+	output := flag.String("output", "packet", "Request output: packet (ICA packet JSON) or msgs (JSON array of messages)")
+	expiration := flag.Duration("expiration", 0, "Action expiration offset from now (request mode; default: SDK choice)")
+	actionID := flag.String("action-id", "", "Action to finalize (finalize-metadata mode)")
+	oldAddress := flag.String("old-address", "", "Pastel address to claim (claim mode)")
+	pubKey := flag.String("pub-key", "", "Hex Pastel public key of --old-address (claim mode)")
+	signature := flag.String("signature", "", "Hex claim signature (claim mode)")
 	flag.Parse()
 
-	for _, check := range []struct{ name, val string }{
-		{"mnemonic", *mnemonic},
-		{"ica-address", *icaAddress},
-		{"grpc-addr", *grpcAddr},
-		{"chain-id", *chainID},
-		{"file", *filePath},
-	} {
+	ctx := context.Background()
+
+	// Normalise 0.0.0.0 → localhost for host-side connections
+	normalizedGRPC := strings.Replace(*grpcAddr, "0.0.0.0", "localhost", 1)
+
+	switch *mode {
+	case "request":
+		requireFlags(
+			flagValue{"mnemonic", *mnemonic},
+			flagValue{"grpc-addr", *grpcAddr},
+			flagValue{"chain-id", *chainID},
+			flagValue{"file", *filePaths},
+		)
+		if *output == "packet" {
+			requireFlags(flagValue{"ica-address", *icaAddress})
+		}
+		buildRequest(ctx, *mnemonic, *icaAddress, normalizedGRPC, *chainID, strings.Split(*filePaths, ","), *ownerHRP, *output, *expiration)
+	case "finalize-metadata":
+		requireFlags(
+			flagValue{"grpc-addr", *grpcAddr},
+			flagValue{"action-id", *actionID},
+		)
+		buildFinalizeMetadata(ctx, normalizedGRPC, *actionID)
+	case "claim":
+		requireFlags(
+			flagValue{"ica-address", *icaAddress},
+			flagValue{"old-address", *oldAddress},
+			flagValue{"pub-key", *pubKey},
+			flagValue{"signature", *signature},
+		)
+		buildClaimPacket(*icaAddress, *oldAddress, *pubKey, *signature)
+	default:
+		fatal("unknown --mode %q (want request, finalize-metadata or claim)", *mode)
+	}
+}
+
+type flagValue struct{ name, val string }
+
+func requireFlags(flags ...flagValue) {
+	for _, check := range flags {
 		if strings.TrimSpace(check.val) == "" {
 			fmt.Fprintf(os.Stderr, "--%s is required\n", check.name)
 			os.Exit(1)
 		}
 	}
+}
 
-	ctx := context.Background()
+// buildRequest prints cascade MsgRequestActions, one per file in filePaths,
+// either wrapped in a single ICA packet (output "packet") or as a JSON array
+// of proto-JSON messages for a regular tx (output "msgs"). The creator is
+// icaAddress if set, otherwise the mnemonic's own Lumera address. A non-zero
+// expiration replaces the SDK-computed expiration time with now+expiration.
+func buildRequest(ctx context.Context, mnemonic, icaAddress, grpcAddr, chainID string, filePaths []string, ownerHRP, output string, expiration time.Duration) {
 
 	// Set up a temporary keyring and import the mnemonic. This must be the
 	// same mnemonic used to create the test user on Osmosis — it derives the
@@ -71,7 +144,7 @@ func main() {
 
 	keyName := "buildpacket-key"
 	keyType := sdkcrypto.KeyTypeCosmos
-	_, err = kr.NewAccount(keyName, *mnemonic, "", keyType.HDPath(), keyType.SigningAlgo())
+	_, err = kr.NewAccount(keyName, mnemonic, "", keyType.HDPath(), keyType.SigningAlgo())
 	if err != nil {
 		fatal("import key from mnemonic: %v", err)
 	}
@@ -92,17 +165,15 @@ func main() {
 	}
 	appPubkey := pub.Bytes()
 
-	// Normalise 0.0.0.0 → localhost for host-side connections
-	normalizedGRPC := strings.Replace(*grpcAddr, "0.0.0.0", "localhost", 1)
-	fmt.Fprintf(os.Stderr, "Connecting to Lumera gRPC: %s\n", normalizedGRPC)
+	fmt.Fprintf(os.Stderr, "Connecting to Lumera gRPC: %s\n", grpcAddr)
 
 	cascadeClient, err := cascade.New(ctx, cascade.Config{
-		ChainID:         *chainID,
-		GRPCAddr:        normalizedGRPC,
+		ChainID:         chainID,
+		GRPCAddr:        grpcAddr,
 		Address:         lumeraAddr,
 		KeyName:         keyName,
 		ICAOwnerKeyName: keyName,
-		ICAOwnerHRP:     *ownerHRP,
+		ICAOwnerHRP:     ownerHRP,
 	}, kr)
 	if err != nil {
 		fatal("create cascade client: %v", err)
@@ -114,26 +185,48 @@ func main() {
 	// (not the local lumera address), since the host chain will execute the
 	// message as the ICA.
 	uploadOpts := &cascade.UploadOptions{}
-	cascade.WithICACreatorAddress(*icaAddress)(uploadOpts)
-	cascade.WithAppPubkey(appPubkey)(uploadOpts)
-
-	msg, _, err := cascadeClient.CreateRequestActionMessage(ctx, lumeraAddr, *filePath, uploadOpts)
-	if err != nil {
-		fatal("CreateRequestActionMessage: %v", err)
+	if icaAddress != "" {
+		cascade.WithICACreatorAddress(icaAddress)(uploadOpts)
+		cascade.WithAppPubkey(appPubkey)(uploadOpts)
 	}
-	fmt.Fprintf(os.Stderr, "Built MsgRequestAction: creator=%s type=%s\n", msg.Creator, msg.ActionType)
 
-	// Pack the message into an ICA CosmosTx envelope. This is the format
-	// that the ICS-27 host module expects: a protobuf-encoded CosmosTx
-	// containing one or more sdk.Msg, base64-encoded into a JSON packet.
-	msgAny, err := ica.PackRequestAny(msg)
-	if err != nil {
-		fatal("PackRequestAny: %v", err)
+	msgs := make([]*actiontypes.MsgRequestAction, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		msg, _, err := cascadeClient.CreateRequestActionMessage(ctx, lumeraAddr, filePath, uploadOpts)
+		if err != nil {
+			fatal("CreateRequestActionMessage %s: %v", filePath, err)
+		}
+		if expiration > 0 {
+			msg.ExpirationTime = fmt.Sprintf("%d", time.Now().Add(expiration).Unix())
+		}
+		fmt.Fprintf(os.Stderr, "Built MsgRequestAction: creator=%s type=%s expiration=%s\n", msg.Creator, msg.ActionType, msg.ExpirationTime)
+		msgs = append(msgs, msg)
 	}
 
-	cosmosTx := &icatypes.CosmosTx{
-		Messages: []*codectypes.Any{msgAny},
+	switch output {
+	case "packet":
+		anys := make([]*codectypes.Any, 0, len(msgs))
+		for _, msg := range msgs {
+			msgAny, err := ica.PackRequestAny(msg)
+			if err != nil {
+				fatal("PackRequestAny: %v", err)
+			}
+			anys = append(anys, msgAny)
+		}
+		printICAPacket(anys)
+	case "msgs":
+		printMsgsJSON(msgs)
+	default:
+		fatal("unknown --output %q (want packet or msgs)", output)
 	}
+}
+
+// printICAPacket prints msgs packed into an ICA CosmosTx envelope. This is
+// the format that the ICS-27 host module expects: a protobuf-encoded CosmosTx
+// containing one or more sdk.Msg, base64-encoded into a JSON packet. All
+// messages execute atomically on the host.
+func printICAPacket(msgs []*codectypes.Any) {
+	cosmosTx := &icatypes.CosmosTx{Messages: msgs}
 	cosmosTxBytes, err := gogoproto.Marshal(cosmosTx)
 	if err != nil {
 		fatal("marshal CosmosTx: %v", err)
@@ -145,6 +238,86 @@ func main() {
 		base64.StdEncoding.EncodeToString(cosmosTxBytes))
 }
 
+// printMsgsJSON prints msgs as a JSON array of proto-JSON objects with an
+// "@type" field, ready to be placed in an unsigned tx body.
+func printMsgsJSON(msgs []*actiontypes.MsgRequestAction) {
+	out := make([]map[string]interface{}, 0, len(msgs))
+	for _, msg := range msgs {
+		js, err := (&jsonpb.Marshaler{}).MarshalToString(msg)
+		if err != nil {
+			fatal("marshal %T: %v", msg, err)
+		}
+		obj := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(js), &obj); err != nil {
+			fatal("decode %T JSON: %v", msg, err)
+		}
+		obj["@type"] = "/" + gogoproto.MessageName(msg)
+		out = append(out, obj)
+	}
+	bz, err := json.Marshal(out)
+	if err != nil {
+		fatal("marshal msgs: %v", err)
+	}
+	fmt.Print(string(bz))
+}
+
+// buildFinalizeMetadata prints the MsgFinalizeAction metadata for a pending
+// cascade action. The rq_ids_ids are derived exactly as a supernode does:
+// from the index signature format stored in the action's metadata, for
+// counters rq_ids_ic..rq_ids_ic+rq_ids_max-1. Storing the RaptorQ symbols is
+// not needed for the chain to accept the finalization.
+func buildFinalizeMetadata(ctx context.Context, grpcAddr, actionID string) {
+	conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		fatal("connect to gRPC %s: %v", grpcAddr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	resp, err := actiontypes.NewQueryClient(conn).GetAction(ctx, &actiontypes.QueryGetActionRequest{ActionID: actionID})
+	if err != nil {
+		fatal("get action %s: %v", actionID, err)
+	}
+	if resp.Action.ActionType != actiontypes.ActionTypeCascade {
+		fatal("action %s is %s, only cascade actions are supported", actionID, resp.Action.ActionType)
+	}
+
+	var meta actiontypes.CascadeMetadata
+	if err := gogoproto.Unmarshal(resp.Action.Metadata, &meta); err != nil {
+		fatal("decode cascade metadata: %v", err)
+	}
+
+	rqIDs, err := cascadekit.GenerateIndexIDs(meta.Signatures, uint32(meta.RqIdsIc), uint32(meta.RqIdsMax))
+	if err != nil {
+		fatal("generate rq ids: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Generated %d rq_ids_ids for action %s (ic=%d max=%d)\n", len(rqIDs), actionID, meta.RqIdsIc, meta.RqIdsMax)
+
+	// Same encoding the supernode uses when building MsgFinalizeAction.
+	out, err := json.Marshal(&actiontypes.CascadeMetadata{RqIdsIds: rqIDs})
+	if err != nil {
+		fatal("marshal finalize metadata: %v", err)
+	}
+	fmt.Print(string(out))
+}
+
+// buildClaimPacket prints an ICA packet with a MsgClaim crediting the Pastel
+// balance of oldAddress to the ICA. The ICA is both the claim destination
+// and the message signer, so the host executes it on the ICA's behalf.
+func buildClaimPacket(icaAddress, oldAddress, pubKey, signature string) {
+	msg := &claimtypes.MsgClaim{
+		OldAddress: oldAddress,
+		NewAddress: icaAddress,
+		PubKey:     pubKey,
+		Signature:  signature,
+	}
+	msgAny, err := codectypes.NewAnyWithValue(msg)
+	if err != nil {
+		fatal("pack MsgClaim: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Built MsgClaim: old=%s new=%s\n", oldAddress, icaAddress)
+	printICAPacket([]*codectypes.Any{msgAny})
+}
+
 func fatal(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "buildpacket: "+format+"\n", args...)
 	os.Exit(1)
@@ -0,0 +1,235 @@
+// transfer_test.go — ICS-20 token transfers between Lumera and Osmosis over
+// the same IBC path the ICA tests use. LUME goes to Osmosis and back, OSMO
+// comes into Lumera, and every hop is checked against the denom trace of the
+// voucher and the escrow account of the sending channel. Holding IBC
+// vouchers must not get in the way of paying Lumera gas in native LUME.
+package interchaintest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// transferPort is the ICS-20 port bound on both chains.
+	transferPort = "transfer"
+	// transferAmount is moved by each transfer.
+	transferAmount = 1_000_000_000
+)
+
+// TestLumeraOsmosisTransfer moves LUME and OSMO across the Lumera ⇄ Osmosis
+// transfer channel. The Osmosis version is the first of OSMOSIS_VERSIONS, or
+// the registry default.
+func TestLumeraOsmosisTransfer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping ICS-20 e2e test in short mode")
+	}
+
+	ctx := context.Background()
+	version, useLocal := lumeraVersionFromEnv()
+	osmosisChain, osmosisVersion, err := osmosisFromEnv()
+	require.NoError(t, err)
+
+	t.Logf("Testing transfers between Lumera %s (local image: %v) and Osmosis %s", version, useLocal, osmosisVersion)
+
+	env := newICATestEnvWithController(t, ctx, osmosisChain, osmosisVersion, GetLumeraChainConfig(version, useLocal))
+	lumera, osmosis := env.lumera, env.controller
+	lumeraDenom, osmoDenom := lumera.Config().Denom, osmosis.Config().Denom
+
+	lumeraUser := interchaintest.GetAndFundTestUsers(t, ctx, "transfer", math.NewInt(10_000_000_000), lumera)[0]
+	osmosisUser := env.user
+
+	lumeraCh := channelByPort(t, ctx, env, lumera, transferPort)
+	osmosisCh := channelByPort(t, ctx, env, osmosis, transferPort)
+	require.Equal(t, osmosisCh.ChannelID, lumeraCh.Counterparty.ChannelID, "transfer channel ends should match")
+
+	lumeOnOsmosis := ibcDenom(osmosisCh.PortID, osmosisCh.ChannelID, lumeraDenom)
+	osmoOnLumera := ibcDenom(lumeraCh.PortID, lumeraCh.ChannelID, osmoDenom)
+	lumeraEscrow := escrowAddress(t, lumera, lumeraCh)
+	osmosisEscrow := escrowAddress(t, osmosis, osmosisCh)
+	amount := math.NewInt(transferAmount)
+
+	escrowedLume, err := lumera.GetBalance(ctx, lumeraEscrow, lumeraDenom)
+	require.NoError(t, err)
+
+	t.Run("LumeToOsmosis", func(t *testing.T) {
+		senderBefore, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), lumeraDenom)
+		require.NoError(t, err)
+
+		tx := sendTransfer(t, ctx, env, lumera, lumeraCh, lumeraUser, osmosisUser.FormattedAddress(), lumeraDenom, amount)
+		waitForBalance(t, ctx, osmosis, osmosisUser.FormattedAddress(), lumeOnOsmosis, amount)
+
+		requireDenomTrace(t, ctx, osmosis, lumeOnOsmosis, transferPort+"/"+osmosisCh.ChannelID, lumeraDenom)
+		escrow, err := lumera.GetBalance(ctx, lumeraEscrow, lumeraDenom)
+		require.NoError(t, err)
+		require.Equal(t, escrowedLume.Add(amount).String(), escrow.String(), "sent LUME should be escrowed on Lumera")
+
+		fee := lumera.GetGasFeesInNativeDenom(tx.GasSpent)
+		sender, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), lumeraDenom)
+		require.NoError(t, err)
+		require.Equal(t, senderBefore.Sub(amount).SubRaw(fee).String(), sender.String())
+	})
+
+	t.Run("LumeBackToLumera", func(t *testing.T) {
+		receiverBefore, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), lumeraDenom)
+		require.NoError(t, err)
+
+		sendTransfer(t, ctx, env, osmosis, osmosisCh, osmosisUser, lumeraUser.FormattedAddress(), lumeOnOsmosis, amount)
+		// Returning vouchers unwind to native ulume, not a double-wrapped
+		// ibc/ denom.
+		waitForBalance(t, ctx, lumera, lumeraUser.FormattedAddress(), lumeraDenom, receiverBefore.Add(amount))
+
+		vouchers, err := osmosis.GetBalance(ctx, osmosisUser.FormattedAddress(), lumeOnOsmosis)
+		require.NoError(t, err)
+		require.True(t, vouchers.IsZero(), "returned vouchers should be burned on Osmosis, have %s", vouchers)
+		escrow, err := lumera.GetBalance(ctx, lumeraEscrow, lumeraDenom)
+		require.NoError(t, err)
+		require.Equal(t, escrowedLume.String(), escrow.String(), "returned LUME should be released from escrow")
+	})
+
+	t.Run("OsmoToLumera", func(t *testing.T) {
+		escrowBefore, err := osmosis.GetBalance(ctx, osmosisEscrow, osmoDenom)
+		require.NoError(t, err)
+
+		sendTransfer(t, ctx, env, osmosis, osmosisCh, osmosisUser, lumeraUser.FormattedAddress(), osmoDenom, amount)
+		waitForBalance(t, ctx, lumera, lumeraUser.FormattedAddress(), osmoOnLumera, amount)
+
+		requireDenomTrace(t, ctx, lumera, osmoOnLumera, transferPort+"/"+lumeraCh.ChannelID, osmoDenom)
+		escrow, err := osmosis.GetBalance(ctx, osmosisEscrow, osmoDenom)
+		require.NoError(t, err)
+		require.Equal(t, escrowBefore.Add(amount).String(), escrow.String(), "sent OSMO should be escrowed on Osmosis")
+	})
+
+	t.Run("NativeGasKeepsIBCAssets", func(t *testing.T) {
+		recipient, err := lumera.BuildWallet(ctx, "transfer-recipient", "")
+		require.NoError(t, err)
+		lumeBefore, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), lumeraDenom)
+		require.NoError(t, err)
+		osmoBefore, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), osmoOnLumera)
+		require.NoError(t, err)
+		require.True(t, osmoBefore.IsPositive(), "user should hold OSMO vouchers")
+
+		send := math.NewInt(1_000_000)
+		res := broadcastMsgs(t, ctx, lumera, lumeraUser.KeyName(), msgSend(lumeraUser.FormattedAddress(), recipient.FormattedAddress(), lumeraDenom, send))
+		requireTxSuccess(t, res)
+
+		fee := lumera.GetGasFeesInNativeDenom(defaultTxGas)
+		lume, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), lumeraDenom)
+		require.NoError(t, err)
+		require.Equal(t, lumeBefore.Sub(send).SubRaw(fee).String(), lume.String(), "amount and gas should be paid in ulume")
+		osmo, err := lumera.GetBalance(ctx, lumeraUser.FormattedAddress(), osmoOnLumera)
+		require.NoError(t, err)
+		require.Equal(t, osmoBefore.String(), osmo.String(), "IBC assets should be untouched by gas payment")
+	})
+}
+
+// sendTransfer sends amount of denom from sender on chain to receiver over
+// channel (chain's end), relays it, and returns the transfer tx.
+func sendTransfer(
+	t *testing.T, ctx context.Context, env *icaTestEnv,
+	chain *cosmos.CosmosChain, channel ibc.ChannelOutput,
+	sender ibc.Wallet, receiver, denom string, amount math.Int,
+) ibc.Tx {
+	t.Helper()
+	tx, err := chain.SendIBCTransfer(ctx, channel.ChannelID, sender.KeyName(), ibc.WalletAmount{
+		Address: receiver,
+		Denom:   denom,
+		Amount:  amount,
+	}, ibc.TransferOptions{})
+	require.NoError(t, err)
+	t.Logf("Transfer %s%s %s/%s -> %s: tx %s", amount, denom, chain.Config().Name, channel.ChannelID, receiver, tx.TxHash)
+	flushPath(t, ctx, env, chain, channel.ChannelID, channel.Counterparty.ChannelID)
+	return tx
+}
+
+// waitForBalance polls until addr holds exactly want of denom on chain.
+func waitForBalance(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, addr, denom string, want math.Int) {
+	t.Helper()
+	var last math.Int
+	require.Eventually(t, func() bool {
+		bal, err := chain.GetBalance(ctx, addr, denom)
+		if err != nil {
+			return false
+		}
+		last = bal
+		return bal.Equal(want)
+	}, time.Minute, 3*time.Second, "%s balance of %s on %s: want %s", denom, addr, chain.Config().Name, want)
+	t.Logf("%s holds %s%s on %s", addr, last, denom, chain.Config().Name)
+}
+
+// ibcDenom returns the voucher denom of baseDenom received over the channel
+// whose receiving end is port/channel.
+func ibcDenom(port, channel, baseDenom string) string {
+	return transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(port, channel, baseDenom)).IBCDenom()
+}
+
+// escrowAddress returns the account holding tokens sent out over chain's end
+// of channel.
+func escrowAddress(t *testing.T, chain *cosmos.CosmosChain, channel ibc.ChannelOutput) string {
+	t.Helper()
+	addr, err := bech32.ConvertAndEncode(chain.Config().Bech32Prefix, transfertypes.GetEscrowAddress(channel.PortID, channel.ChannelID))
+	require.NoError(t, err)
+	return addr
+}
+
+// requireDenomTrace checks that the voucher denom ("ibc/<hash>") resolves to
+// path and baseDenom on chain.
+func requireDenomTrace(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, denom, path, baseDenom string) {
+	t.Helper()
+	gotPath, gotBase, err := queryDenomTrace(ctx, chain, denom)
+	require.NoError(t, err)
+	require.Equal(t, path, gotPath, "denom trace path of %s", denom)
+	require.Equal(t, baseDenom, gotBase, "base denom of %s", denom)
+}
+
+// queryDenomTrace resolves a voucher denom on chain to its trace path and base
+// denom. ibc-go v10 replaced the denom-trace query with denom, so both are
+// tried.
+func queryDenomTrace(ctx context.Context, chain *cosmos.CosmosChain, denom string) (string, string, error) {
+	hash := strings.TrimPrefix(denom, "ibc/")
+
+	var v10 struct {
+		Denom struct {
+			Base  string `json:"base"`
+			Trace []struct {
+				PortID    string `json:"port_id"`
+				ChannelID string `json:"channel_id"`
+			} `json:"trace"`
+		} `json:"denom"`
+	}
+	if err := queryJSON(ctx, chain, &v10, "ibc-transfer", "denom", hash); err == nil && v10.Denom.Base != "" {
+		hops := make([]string, 0, len(v10.Denom.Trace))
+		for _, hop := range v10.Denom.Trace {
+			hops = append(hops, hop.PortID+"/"+hop.ChannelID)
+		}
+		return strings.Join(hops, "/"), v10.Denom.Base, nil
+	}
+
+	var v8 struct {
+		DenomTrace struct {
+			Path      string `json:"path"`
+			BaseDenom string `json:"base_denom"`
+		} `json:"denom_trace"`
+	}
+	if err := queryJSON(ctx, chain, &v8, "ibc-transfer", "denom-trace", hash); err != nil {
+		return "", "", err
+	}
+	return v8.DenomTrace.Path, v8.DenomTrace.BaseDenom, nil
+}
+
+func TestIBCDenom(t *testing.T) {
+	// ATOM on Osmosis, received over Osmosis' channel-0.
+	require.Equal(t, "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", ibcDenom(transferPort, "channel-0", "uatom"))
+}
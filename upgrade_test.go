@@ -0,0 +1,191 @@
+// upgrade_test.go — In-place Lumera upgrade with live ICA state. Lumera starts
+// at LUMERA_UPGRADE_FROM, gets an ICA from Osmosis and an action created
+// through it, then upgrades via a software-upgrade proposal to
+// LUMERA_UPGRADE_TO. The ICA channel, address, balance and actions must
+// survive, and new ICA packets must still execute.
+package interchaintest_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"golang.org/x/mod/semver"
+
+	"github.com/strangelove-ventures/interchaintest/v8"
+	"github.com/strangelove-ventures/interchaintest/v8/chain/cosmos"
+	"github.com/strangelove-ventures/interchaintest/v8/ibc"
+	"github.com/strangelove-ventures/interchaintest/v8/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// upgradeHaltHeightDelta is how far ahead of the proposal the upgrade
+	// is scheduled; it must outlast upgradeVotingPeriod.
+	upgradeHaltHeightDelta = 40
+	upgradeVotingPeriod    = 15 * time.Second
+	// upgradeProposalDeposit covers the genesis min_deposit of 1,000 LUME.
+	upgradeProposalDeposit = 1_000_000_000
+	// icaHostPort is the port of interchain account channels on the host.
+	icaHostPort = "icahost"
+)
+
+// TestLumeraUpgradePreservesICA upgrades Lumera under a registered ICA.
+//
+// LUMERA_UPGRADE_FROM selects the starting release (the test is skipped
+// without it), LUMERA_UPGRADE_TO the target (default LUMERA_VERSION, or the
+// local image with USE_LOCAL_IMAGE) and LUMERA_UPGRADE_NAME the upgrade plan
+// name the target registers a handler for (default the target version).
+func TestLumeraUpgradePreservesICA(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping upgrade e2e test in short mode")
+	}
+	from := os.Getenv("LUMERA_UPGRADE_FROM")
+	if from == "" {
+		t.Skip("LUMERA_UPGRADE_FROM not set")
+	}
+	to, useLocal := lumeraVersionFromEnv()
+	if v := os.Getenv("LUMERA_UPGRADE_TO"); v != "" {
+		to = v
+	}
+	planName := os.Getenv("LUMERA_UPGRADE_NAME")
+	if planName == "" {
+		planName = to
+	}
+	if f, tgt := normalizeLumeraVersion(from), normalizeLumeraVersion(to); f != "" && tgt != "" {
+		require.Negative(t, semver.Compare(f, tgt), "LUMERA_UPGRADE_FROM %s must be older than %s", from, to)
+	}
+
+	ctx := context.Background()
+	lumeraConfig := GetLumeraChainConfig(from, false,
+		WithUpgradeTo(to),
+		WithSupernodeMinimumStake(math.NewInt(supernodeTestMinStake)),
+		WithGenesisKV("app_state.gov.params.voting_period", durationParam(upgradeVotingPeriod)),
+		WithGenesisKV("app_state.gov.params.expedited_voting_period", durationParam(upgradeVotingPeriod/2)),
+		WithGenesisKV("app_state.gov.params.max_deposit_period", durationParam(upgradeVotingPeriod)),
+	)
+	targetImage := GetLumeraChainConfig(to, useLocal).Images[0]
+
+	t.Logf("Testing upgrade %s -> %s:%s (plan %q)", from, targetImage.Repository, targetImage.Version, planName)
+
+	env := newICATestEnv(t, ctx, lumeraConfig)
+	registerICA(t, ctx, env)
+	fundICA(t, ctx, env.lumera, env.icaAddr)
+	lumera := env.lumera
+	denom := lumera.Config().Denom
+
+	// ── Before: ICA with an action ──
+	registerTestSupernode(t, ctx, lumera)
+	port, seq := sendICAPacket(t, ctx, env, buildCascadePacket(t, ctx, env))
+	ack := queryICAAck(t, ctx, lumera, port, seq)
+	require.True(t, ack.Success(), "ICA MsgRequestAction failed before upgrade: %s", ack.Error)
+
+	channelBefore := icaHostChannel(t, ctx, env)
+	balanceBefore, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+	require.NoError(t, err)
+	actionsBefore := actionsByCreator(t, ctx, lumera, env.icaAddr)
+	require.Len(t, actionsBefore, 1)
+
+	// ── Upgrade ──
+	upgradeLumera(t, ctx, env, planName, targetImage)
+
+	// ── After: state preserved ──
+	t.Run("ChannelPreserved", func(t *testing.T) {
+		channel := icaHostChannel(t, ctx, env)
+		require.Equal(t, channelBefore.ChannelID, channel.ChannelID)
+		require.Equal(t, channelBefore.Counterparty, channel.Counterparty)
+		require.Equal(t, "STATE_OPEN", channel.State)
+	})
+
+	t.Run("AddressPreserved", func(t *testing.T) {
+		addr, err := tryQueryICAAddress(ctx, env.controller, env.connectionID, env.user.FormattedAddress())
+		require.NoError(t, err)
+		require.Equal(t, env.icaAddr, addr)
+	})
+
+	t.Run("BalancePreserved", func(t *testing.T) {
+		balance, err := lumera.GetBalance(ctx, env.icaAddr, denom)
+		require.NoError(t, err)
+		require.Equal(t, balanceBefore.String(), balance.String())
+	})
+
+	t.Run("ActionsPreserved", func(t *testing.T) {
+		actions := actionsByCreator(t, ctx, lumera, env.icaAddr)
+		require.Len(t, actions, len(actionsBefore))
+		for i, a := range actionsBefore {
+			require.Equal(t, a.ActionID, actions[i].ActionID)
+			require.Equal(t, a.ActionType, actions[i].ActionType)
+			require.Equal(t, a.State, actions[i].State)
+		}
+	})
+
+	t.Run("NewPacketExecutes", func(t *testing.T) {
+		port, seq := sendICAPacket(t, ctx, env, buildCascadePacket(t, ctx, env))
+		ack := queryICAAck(t, ctx, lumera, port, seq)
+		require.True(t, ack.Success(), "ICA MsgRequestAction failed after upgrade: %s", ack.Error)
+		require.Len(t, actionsByCreator(t, ctx, lumera, env.icaAddr), len(actionsBefore)+1)
+	})
+}
+
+// upgradeLumera passes a software-upgrade proposal for planName, waits for
+// the chain to halt at the upgrade height and restarts it on image.
+func upgradeLumera(t *testing.T, ctx context.Context, env *icaTestEnv, planName string, image ibc.DockerImage) {
+	t.Helper()
+	lumera := env.lumera
+	denom := lumera.Config().Denom
+
+	proposer := interchaintest.GetAndFundTestUsers(t, ctx, "proposer", math.NewInt(2*upgradeProposalDeposit), lumera)[0]
+
+	height, err := lumera.Height(ctx)
+	require.NoError(t, err)
+	haltHeight := height + upgradeHaltHeightDelta
+
+	tx, err := lumera.UpgradeProposal(ctx, proposer.KeyName(), cosmos.SoftwareUpgradeProposal{
+		Deposit:     strconv.Itoa(upgradeProposalDeposit) + denom,
+		Title:       "Upgrade to " + planName,
+		Name:        planName,
+		Description: "interchaintest in-place upgrade",
+		Height:      haltHeight,
+		Proposer:    proposer.FormattedAddress(),
+	})
+	require.NoError(t, err, "submit upgrade proposal")
+	proposalID, err := strconv.ParseUint(tx.ProposalID, 10, 64)
+	require.NoError(t, err)
+
+	require.NoError(t, lumera.VoteOnProposalAllValidators(ctx, proposalID, cosmos.ProposalVoteYes))
+	_, err = cosmos.PollForProposalStatusV1(ctx, lumera, height, haltHeight, proposalID, govv1.StatusPassed)
+	require.NoError(t, err, "upgrade proposal did not pass before the halt height")
+	t.Logf("Upgrade proposal %d passed; halting at height %d", proposalID, haltHeight)
+
+	// The chain stops producing blocks at the halt height, so this wait
+	// times out.
+	height, err = lumera.Height(ctx)
+	require.NoError(t, err)
+	haltCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	_ = testutil.WaitForBlocks(haltCtx, int(haltHeight-height)+1, lumera)
+
+	height, err = lumera.Height(ctx)
+	require.NoError(t, err)
+	require.Equal(t, haltHeight, height, "chain should halt at the upgrade height")
+
+	require.NoError(t, lumera.StopAllNodes(ctx))
+	lumera.UpgradeVersion(ctx, env.docker, image.Repository, image.Version)
+	require.NoError(t, lumera.StartAllNodes(ctx))
+
+	resumeCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	require.NoError(t, testutil.WaitForBlocks(resumeCtx, 5, lumera), "chain did not produce blocks after upgrade")
+	t.Logf("Lumera upgraded to %s:%s", image.Repository, image.Version)
+}
+
+// icaHostChannel returns the host end of env's ICA channel on Lumera.
+func icaHostChannel(t *testing.T, ctx context.Context, env *icaTestEnv) ibc.ChannelOutput {
+	t.Helper()
+	return channelByPort(t, ctx, env, env.lumera, icaHostPort)
+}